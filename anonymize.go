@@ -0,0 +1,111 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// hash keeps the same values joinable, redact replaces by the mask
+var anonymizeModes = []string{"hash", "redact"}
+
+// Parse `name,phone:redact` to the mode by column, hash by default
+func parseAnonymize(spec string) (map[string]string, error) {
+	columns := map[string]string{}
+	for _, item := range strings.Split(spec, ",") {
+		parts := strings.SplitN(strings.TrimSpace(item), ":", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("Empty column in `%s'", spec)
+		}
+		mode := "hash"
+		if len(parts) == 2 {
+			mode = parts[1]
+		}
+		if !contains(anonymizeModes, mode) {
+			return nil, fmt.Errorf("Unknown anonymize mode `%s', expect %s", mode, strings.Join(anonymizeModes, ", "))
+		}
+		columns[parts[0]] = mode
+	}
+	return columns, nil
+}
+
+// The key of the export hashing the values, shown to reuse by `--anonymize-key`,
+// so the exports by the same key are joinable while the values can't be brute forced without it
+func randomAnonymizeKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// Whether any column is hashed, which requires the key
+func hashesColumns(columns map[string]string) bool {
+	for _, mode := range columns {
+		if mode == "hash" {
+			return true
+		}
+	}
+	return false
+}
+
+// HMAC-SHA256 of the typed value, e.g. the string "1" differs from the integer 1
+func anonymizeValue(value *common.Value, mode string, key []byte) *common.Value {
+	if value.IsSetNVal() {
+		return value
+	}
+	if mode == "redact" {
+		return &common.Value{SVal: []byte(secretMask)}
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(exactString(value)))
+	return &common.Value{SVal: []byte(hex.EncodeToString(mac.Sum(nil)[:16]))}
+}
+
+// Replace the values of the anonymized columns, all of them must exist
+func anonymizeTables(tables []*graph.DataSet, columns map[string]string, key []byte) ([]*graph.DataSet, error) {
+	if len(columns) == 0 {
+		return tables, nil
+	}
+	anonymized := make([]*graph.DataSet, len(tables))
+	found := map[string]bool{}
+	for i, table := range tables {
+		modes := make([]string, len(table.GetColumnNames()))
+		for j, name := range columnNames(table) {
+			if mode, ok := columns[name]; ok {
+				modes[j] = mode
+				found[name] = true
+			}
+		}
+		result := &graph.DataSet{ColumnNames: table.GetColumnNames()}
+		for _, row := range table.GetRows() {
+			values := make([]*common.Value, len(row.GetColumns()))
+			for j, col := range row.GetColumns() {
+				values[j] = col
+				if j < len(modes) && modes[j] != "" {
+					values[j] = anonymizeValue(col, modes[j], key)
+				}
+			}
+			result.Rows = append(result.Rows, &graph.Row{Columns: values})
+		}
+		anonymized[i] = result
+	}
+	for name := range columns {
+		if !found[name] {
+			return nil, fmt.Errorf("Anonymized column `%s' not found", name)
+		}
+	}
+	return anonymized, nil
+}
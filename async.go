@@ -0,0 +1,168 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The statement executed in the background by `:async`
+type asyncJob struct {
+	id    int
+	stmt  string
+	space string
+	start time.Time
+	// Set when done
+	elapsed time.Duration
+	resp    *graph.ExecutionResponse
+	err     error
+	done    chan struct{}
+}
+
+var (
+	asyncMutex     sync.Mutex
+	asyncJobs      = map[int]*asyncJob{}
+	lastAsyncJobID = 0
+)
+
+// Execute the statement by the dedicated session to not block the interactive one
+func (j *asyncJob) run() {
+	defer close(j.done)
+	defer func() {
+		j.elapsed = time.Since(j.start)
+	}()
+	client, err := newSession(conn)
+	if err != nil {
+		j.err = fmt.Errorf("Connect failed, %s", err.Error())
+		return
+	}
+	client.background = true
+	defer client.Disconnect()
+	if j.space != "" {
+		resp, err := client.Execute("USE " + quoteName(j.space))
+		if err != nil {
+			j.err = fmt.Errorf("USE %s failed, %s", j.space, err.Error())
+			return
+		}
+		// Not executed in the default space instead
+		if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			j.err = fmt.Errorf("USE %s failed, %s", j.space, errorString(resp))
+			return
+		}
+	}
+	j.resp, j.err = client.Execute(j.stmt)
+}
+
+// Notify the finished job above the prompt
+func (j *asyncJob) notice() {
+	<-j.done
+	printBackground("[JOB #%d] %s in %s, show by `:result %d'.", j.id, j.status(), j.elapsed.Round(time.Millisecond), j.id)
+}
+
+func (j *asyncJob) status() string {
+	select {
+	case <-j.done:
+	default:
+		return "running"
+	}
+	switch {
+	case j.err != nil:
+		return "error"
+	case j.resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED:
+		return "failed"
+	}
+	return "succeeded"
+}
+
+func lookupAsyncJob(arg string) (*asyncJob, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(arg), "#"))
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+	j, ok := asyncJobs[id]
+	if err != nil || !ok {
+		return nil, fmt.Errorf("Unknown job `%s'", strings.TrimSpace(arg))
+	}
+	return j, nil
+}
+
+// :async <statement>
+func asyncCmd(client *Session, c Cli, args string) error {
+	stmt, err := substituteVariables(strings.TrimSpace(args))
+	if err != nil {
+		return err
+	}
+	if stmt == "" {
+		return fmt.Errorf("Usage: :async <statement>")
+	}
+	if err = confirmStatement(c, stmt); err != nil {
+		return err
+	}
+	// Like the typed statements
+	stmt = autoQuoteVids(client, stmt)
+	asyncMutex.Lock()
+	lastAsyncJobID++
+	j := &asyncJob{id: lastAsyncJobID, stmt: stmt, space: client.space, start: time.Now(), done: make(chan struct{})}
+	asyncJobs[j.id] = j
+	asyncMutex.Unlock()
+	go j.run()
+	go j.notice()
+	fmt.Printf("Submitted job #%d, check by `:jobs' and `:result %d'.", j.id, j.id)
+	fmt.Println()
+	return nil
+}
+
+// :jobs
+func jobsCmd(client *Session, c Cli, args string) error {
+	asyncMutex.Lock()
+	ids := make([]int, 0, len(asyncJobs))
+	for id := range asyncJobs {
+		ids = append(ids, id)
+	}
+	asyncMutex.Unlock()
+	sort.Ints(ids)
+	for _, id := range ids {
+		j, _ := lookupAsyncJob(strconv.Itoa(id))
+		status := j.status()
+		elapsed := time.Since(j.start)
+		if status != "running" {
+			elapsed = j.elapsed
+		}
+		fmt.Printf("#%d %s %s `%s' in space `%s'", id, status, elapsed.Round(time.Millisecond), j.stmt, j.space)
+		fmt.Println()
+	}
+	return nil
+}
+
+// :result <id>, show the result of the finished job and forget it
+func resultCmd(client *Session, c Cli, args string) error {
+	if strings.TrimSpace(args) == "" {
+		return fmt.Errorf("Usage: :result <id>")
+	}
+	j, err := lookupAsyncJob(args)
+	if err != nil {
+		return err
+	}
+	if j.status() == "running" {
+		return fmt.Errorf("Job #%d is still running for %s", j.id, time.Since(j.start).Round(time.Second))
+	}
+	asyncMutex.Lock()
+	delete(asyncJobs, j.id)
+	asyncMutex.Unlock()
+	if j.err != nil {
+		return j.err
+	}
+	printResp(j.resp, j.elapsed, outputFormat, returnsPlan(j.stmt))
+	cacheResp(j.resp, returnsPlan(j.stmt))
+	return nil
+}
@@ -0,0 +1,116 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// The statements buffered between `:batch begin` and `:batch commit`
+var (
+	batching        = false
+	batchStatements []string
+	// The script began the batch, which must commit before its end
+	batchCli Cli
+)
+
+// Buffer the statement instead of executing if batching
+func bufferStatement(stmt string) bool {
+	if !batching {
+		return false
+	}
+	batchStatements = append(batchStatements, strings.TrimSpace(stmt))
+	fmt.Printf("Buffered statement %d, submit by `:batch commit'.", len(batchStatements))
+	fmt.Println()
+	fmt.Println()
+	return true
+}
+
+// Submit the buffered statements back-to-back as executed one by one, stop at the first failure if abort
+func commitBatch(client *Session, c Cli, abort bool) error {
+	stmts := batchStatements
+	batching, batchStatements, batchCli = false, nil, nil
+	start := time.Now()
+	succeeded, failed := 0, 0
+	for i, stmt := range stmts {
+		ok, err := runStatement(client, c, stmt)
+		if err != nil {
+			// The batch run stops by --abort-on-error
+			return err
+		}
+		if ok {
+			succeeded++
+			continue
+		}
+		failed++
+		fmt.Print(errorColor(fmt.Sprintf("[ERROR] Statement %d `%s' failed", i+1, stmt)))
+		fmt.Println()
+		if abort {
+			fmt.Printf("Aborted the rest %d statements.", len(stmts)-i-1)
+			fmt.Println()
+			break
+		}
+	}
+	fmt.Printf("Committed %d statements, %d failed in %s.", succeeded, failed, time.Since(start).Round(time.Millisecond))
+	fmt.Println()
+	if failed > 0 {
+		return fmt.Errorf("%d statements failed", failed)
+	}
+	return nil
+}
+
+// The batch not committed by the end of the script it began fails the script instead of being dropped
+func unterminatedBatch(c Cli) error {
+	if !batching || batchCli != c || c.Interactive() {
+		return nil
+	}
+	n := len(batchStatements)
+	batching, batchStatements, batchCli = false, nil, nil
+	fmt.Print(errorColor(fmt.Sprintf("[ERROR] The batch of %d statements not committed by `:batch commit' at the end of the script, discarded", n)))
+	fmt.Println()
+	fmt.Println()
+	return batchFailed(c, exitStatementError)
+}
+
+// :batch begin|commit [--abort]|discard|show
+func batchCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return fmt.Errorf("Usage: :batch begin|commit [--abort]|discard|show")
+	}
+	if fields[0] != "begin" && !batching {
+		return fmt.Errorf("Not batching, `:batch begin' first")
+	}
+	switch fields[0] {
+	case "begin":
+		if batching {
+			return fmt.Errorf("Batching already with %d statements", len(batchStatements))
+		}
+		batching, batchCli = true, c
+		fmt.Println("Buffering the statements until `:batch commit'.")
+	case "commit":
+		abort := len(fields) == 2 && fields[1] == "--abort"
+		if len(fields) > 2 || (len(fields) == 2 && !abort) {
+			return fmt.Errorf("Usage: :batch commit [--abort]")
+		}
+		return commitBatch(client, c, abort)
+	case "discard":
+		fmt.Printf("Discarded %d statements.", len(batchStatements))
+		fmt.Println()
+		batching, batchStatements, batchCli = false, nil, nil
+	case "show":
+		for i, stmt := range batchStatements {
+			fmt.Printf("%5d  %s", i+1, stmt)
+			fmt.Println()
+		}
+	default:
+		return fmt.Errorf("Usage: :batch begin|commit [--abort]|discard|show")
+	}
+	return nil
+}
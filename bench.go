@@ -0,0 +1,206 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The latency of one execution
+type benchSample struct {
+	client time.Duration
+	server time.Duration
+}
+
+// The nearest-rank percentile of the sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func avg(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	total := time.Duration(0)
+	for _, d := range durations {
+		total += d
+	}
+	return total / time.Duration(len(durations))
+}
+
+func ms(d time.Duration) string {
+	return fmt.Sprintf("%.3f", float64(d)/float64(time.Millisecond))
+}
+
+// Retries to replace the broken session
+const benchConnectRetries = 3
+
+// Check the session by ping and replace it if broken, so the timed work never pays the reconnection
+func healthySession(session *Session) (*Session, error) {
+	if session != nil {
+		if _, err := ping(session); err == nil {
+			return session, nil
+		}
+		session.Disconnect()
+	}
+	var err error
+	for i := 0; i < benchConnectRetries; i++ {
+		if session, err = newSession(conn); err != nil {
+			continue
+		}
+		if _, err = ping(session); err == nil {
+			return session, nil
+		}
+		session.Disconnect()
+	}
+	return nil, err
+}
+
+// Execute the statement n times from the concurrent sessions, returns the exit code
+// The sessions are established and checked before timing, and probed every probeInterval
+func runBench(stmt string, n int, concurrency int, probeInterval time.Duration) int {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	pool := make([]*Session, concurrency)
+	defer func() {
+		for _, session := range pool {
+			if session != nil {
+				session.Disconnect()
+			}
+		}
+	}()
+	var wg sync.WaitGroup
+	errs := make([]error, concurrency)
+	for i := range pool {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			pool[i], errs[i] = healthySession(nil)
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			fmt.Printf("[ERROR] Connect session %d failed, %s", i, err.Error())
+			fmt.Println()
+			return errorExitCode(err, exitConnectError)
+		}
+	}
+
+	jobs := make(chan struct{}, n)
+	for i := 0; i < n; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	samples := make([]benchSample, 0, n)
+	failed := 0
+	replaced := 0
+	// The probes and replacements are excluded from the elapsed time
+	var probing int64
+	start := time.Now()
+	for i := range pool {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			broken := false
+			lastProbe := time.Now()
+			for range jobs {
+				if broken || (probeInterval > 0 && time.Since(lastProbe) > probeInterval) {
+					begin := time.Now()
+					session, err := healthySession(pool[i])
+					atomic.AddInt64(&probing, int64(time.Since(begin)))
+					lastProbe = time.Now()
+					mu.Lock()
+					if err != nil {
+						failed++
+					} else if session != pool[i] {
+						replaced++
+					}
+					mu.Unlock()
+					pool[i] = session
+					if err != nil {
+						// Reconnected again by the next job instead of executing by the nil session
+						broken = true
+						continue
+					}
+					broken = false
+				}
+				begin := time.Now()
+				resp, err := pool[i].Execute(stmt)
+				d := time.Since(begin)
+				broken = err != nil
+				mu.Lock()
+				if err != nil || resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+					failed++
+				} else {
+					samples = append(samples, benchSample{d, time.Duration(resp.GetLatencyInUs()) * time.Microsecond})
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	// The average probing time of each session
+	elapsed := time.Since(start) - time.Duration(probing)/time.Duration(concurrency)
+
+	clients := make([]time.Duration, len(samples))
+	servers := make([]time.Duration, len(samples))
+	for i, s := range samples {
+		clients[i] = s.client
+		servers[i] = s.server
+	}
+	sort.Slice(clients, func(i, j int) bool { return clients[i] < clients[j] })
+	sort.Slice(servers, func(i, j int) bool { return servers[i] < servers[j] })
+
+	fmt.Printf("%d executions by %d sessions in %s, %d failed, %.1f qps.", n, concurrency,
+		elapsed.Round(time.Millisecond), failed, float64(len(samples))/elapsed.Seconds())
+	fmt.Println()
+	if replaced > 0 {
+		fmt.Printf("[WARNING] %d broken sessions replaced.", replaced)
+		fmt.Println()
+	}
+	if len(samples) > 0 {
+		fmt.Printf("%-8s %12s %12s", "(ms)", "client", "server")
+		fmt.Println()
+		rows := []struct {
+			name string
+			f    func([]time.Duration) time.Duration
+		}{
+			{"min", func(d []time.Duration) time.Duration { return d[0] }},
+			{"avg", avg},
+			{"p50", func(d []time.Duration) time.Duration { return percentile(d, 50) }},
+			{"p95", func(d []time.Duration) time.Duration { return percentile(d, 95) }},
+			{"p99", func(d []time.Duration) time.Duration { return percentile(d, 99) }},
+			{"max", func(d []time.Duration) time.Duration { return d[len(d)-1] }},
+		}
+		for _, row := range rows {
+			fmt.Printf("%-8s %12s %12s", row.name, ms(row.f(clients)), ms(row.f(servers)))
+			fmt.Println()
+		}
+	}
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,114 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The result named by `:bookmark save`, retained in the session until dropped
+type bookmark struct {
+	resp      *graph.ExecutionResponse
+	explained bool
+	saved     time.Time
+}
+
+var bookmarks = map[string]bookmark{}
+
+const bookmarkUsage = "Usage: :bookmark save <name> | show <name> [n] | list | drop <name> | compare <name> <name>"
+
+// The bookmark by the name, `last' is the last result
+func lookupBookmark(name string) (bookmark, error) {
+	if b, ok := bookmarks[name]; ok {
+		return b, nil
+	}
+	if name == "last" {
+		if lastResp == nil {
+			return bookmark{}, fmt.Errorf("No result")
+		}
+		return bookmark{lastResp, lastExplained, time.Now()}, nil
+	}
+	return bookmark{}, fmt.Errorf("Unknown bookmark `%s'", name)
+}
+
+// :bookmark save|show|list|drop|compare, e.g. `:bookmark save q_supernodes` names the last result
+func bookmarkCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return fmt.Errorf(bookmarkUsage)
+	}
+	switch {
+	case fields[0] == "save" && len(fields) == 2:
+		if lastResp == nil {
+			return fmt.Errorf("No result")
+		}
+		if fields[1] == "last" {
+			return fmt.Errorf("The bookmark `last' is reserved for the last result")
+		}
+		bookmarks[fields[1]] = bookmark{resp: lastResp, explained: lastExplained, saved: time.Now()}
+		fmt.Printf("Bookmarked the last result as `%s'.", fields[1])
+		fmt.Println()
+		return nil
+	case fields[0] == "show" && (len(fields) == 2 || len(fields) == 3):
+		b, err := lookupBookmark(fields[1])
+		if err != nil {
+			return err
+		}
+		if len(fields) == 2 {
+			printResp(b.resp, 0, outputFormat, b.explained)
+			return nil
+		}
+		tables := b.resp.GetData()
+		n, err := strconv.Atoi(fields[2])
+		if err != nil || n < 1 || n > len(tables) {
+			return fmt.Errorf("Expect the result 1 to %d, got `%s'", len(tables), fields[2])
+		}
+		fmt.Fprintf(out, "Result %d/%d", n, len(tables))
+		fmt.Fprintln(out)
+		printData(tables[n-1], outputFormat, b.explained)
+		return nil
+	case fields[0] == "list" && len(fields) == 1:
+		names := make([]string, 0, len(bookmarks))
+		for name := range bookmarks {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			b := bookmarks[name]
+			rows := 0
+			for _, table := range b.resp.GetData() {
+				rows += len(table.GetRows())
+			}
+			fmt.Printf("%s %d tables, %d rows, saved at %s", name, len(b.resp.GetData()), rows, b.saved.Format("15:04:05"))
+			fmt.Println()
+		}
+		return nil
+	case fields[0] == "drop" && len(fields) == 2:
+		if _, ok := bookmarks[fields[1]]; !ok {
+			return fmt.Errorf("Unknown bookmark `%s'", fields[1])
+		}
+		delete(bookmarks, fields[1])
+		return nil
+	case fields[0] == "compare" && len(fields) == 3:
+		a, err := lookupBookmark(fields[1])
+		if err != nil {
+			return err
+		}
+		b, err := lookupBookmark(fields[2])
+		if err != nil {
+			return err
+		}
+		return compareResps(a.resp, b.resp, fields[1], fields[2])
+	}
+	return fmt.Errorf(bookmarkUsage)
+}
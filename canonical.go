@@ -0,0 +1,139 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The key columns of `--canonical`, `*' sorts by all columns
+func parseCanonicalKey(s string) []string {
+	if s == "*" {
+		return []string{}
+	}
+	return strings.Split(s, ",")
+}
+
+// The float rounded to 15 significant digits to hide the noise of the computation, e.g. 0.30000000000000004
+func canonicalFloat(f float64) string {
+	if math.IsNaN(f) {
+		return "NaN"
+	}
+	s := strconv.FormatFloat(f, 'g', 15, 64)
+	// Reparse for the shortest form, and -0 is 0
+	if rounded, err := strconv.ParseFloat(s, 64); err == nil {
+		if rounded == 0 {
+			rounded = 0
+		}
+		s = strconv.FormatFloat(rounded, 'g', -1, 64)
+	}
+	return s
+}
+
+// The floats rounded and the datetimes in UTC at any depth, regardless of the server and the settings
+var canonicalOptions = valueOptions{location: time.UTC, roundFloats: true}
+
+// The scalars exported as they are, the others rendered by the canonical options
+func canonicalValue(value *common.Value) *common.Value {
+	switch {
+	case value.IsSetNVal(), value.IsSetBVal(), value.IsSetIVal(), value.IsSetSVal(), value.IsSetDVal():
+		return value
+	}
+	var b strings.Builder
+	writeValue(&b, value, 256, canonicalOptions)
+	return &common.Value{SVal: []byte(b.String())}
+}
+
+// The integers are compared by the numbers, the others by the exported strings
+func compareCanonical(a, b *common.Value) int {
+	if a.IsSetIVal() && b.IsSetIVal() {
+		switch {
+		case a.GetIVal() < b.GetIVal():
+			return -1
+		case a.GetIVal() > b.GetIVal():
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(exportValue(a), exportValue(b))
+}
+
+// The columns ordered by the names and the rows sorted by the key columns then all columns,
+// so the exports of the same data are identical byte-for-byte
+func canonicalTable(table *graph.DataSet, key []string) (*graph.DataSet, error) {
+	header := columnNames(table)
+	order := make([]int, len(header))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return header[order[i]] < header[order[j]]
+	})
+	canonical := &graph.DataSet{}
+	position := map[string]int{}
+	for i, from := range order {
+		canonical.ColumnNames = append(canonical.ColumnNames, table.GetColumnNames()[from])
+		position[header[from]] = i
+	}
+	keyColumns := []int{}
+	for _, name := range key {
+		i, ok := position[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("Unknown key column `%s', expect one of %s", name, strings.Join(header, ", "))
+		}
+		keyColumns = append(keyColumns, i)
+	}
+	for i := range order {
+		keyColumns = append(keyColumns, i)
+	}
+
+	for _, row := range table.GetRows() {
+		columns := make([]*common.Value, 0, len(order))
+		for _, from := range order {
+			if from < len(row.GetColumns()) {
+				columns = append(columns, canonicalValue(row.GetColumns()[from]))
+			}
+		}
+		canonical.Rows = append(canonical.Rows, &graph.Row{Columns: columns})
+	}
+	sort.SliceStable(canonical.Rows, func(i, j int) bool {
+		a, b := canonical.Rows[i].GetColumns(), canonical.Rows[j].GetColumns()
+		for _, k := range keyColumns {
+			if k >= len(a) || k >= len(b) {
+				return len(a) < len(b)
+			}
+			if c := compareCanonical(a[k], b[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return canonical, nil
+}
+
+func canonicalTables(tables []*graph.DataSet, key []string) ([]*graph.DataSet, error) {
+	if key == nil {
+		return tables, nil
+	}
+	canonical := make([]*graph.DataSet, 0, len(tables))
+	for _, table := range tables {
+		t, err := canonicalTable(table, key)
+		if err != nil {
+			return nil, err
+		}
+		canonical = append(canonical, t)
+	}
+	return canonical, nil
+}
@@ -7,6 +7,9 @@ import (
 	"path"
 	"log"
 	"os"
+	"regexp"
+	"strings"
+	"time"
 
 	readline "github.com/shylock-hg/readline"
 )
@@ -17,91 +20,83 @@ const ttyColorRed = "31"
 const ttyColorBold = "1"
 const ttyColorReset = "0"
 
-var completer = readline.NewPrefixCompleter(
-	// show
-	readline.PcItem("SHOW",
-		readline.PcItem("HOSTS"),
-		readline.PcItem("SPACES"),
-		readline.PcItem("PARTS"),
-		readline.PcItem("TAGS"),
-		readline.PcItem("EDGES"),
-		readline.PcItem("USERS"),
-		readline.PcItem("ROLES"),
-		readline.PcItem("USER"),
-		readline.PcItem("CONFIGS"),
-	),
-
-	// describe
-	readline.PcItem("DESCRIBE",
-		readline.PcItem("TAG"),
-		readline.PcItem("EDGE"),
-		readline.PcItem("SPACE"),
-	),
-	readline.PcItem("DESC",
-		readline.PcItem("TAG"),
-		readline.PcItem("EDGE"),
-		readline.PcItem("SPACE"),
-	),
-	// get configs
-	readline.PcItem("GET",
-		readline.PcItem("CONFIGS"),
-	),
-	// create
-	readline.PcItem("CREATE",
-		readline.PcItem("SPACE"),
-		readline.PcItem("TAG"),
-		readline.PcItem("EDGE"),
-		readline.PcItem("USER"),
-	),
-	// drop
-	readline.PcItem("DROP",
-		readline.PcItem("SPACE"),
-		readline.PcItem("TAG"),
-		readline.PcItem("EDGE"),
-		readline.PcItem("USER"),
-	),
-	// alter
-	readline.PcItem("ALTER",
-		readline.PcItem("USER"),
-		readline.PcItem("TAG"),
-		readline.PcItem("EDGE"),
-	),
-
-	// insert
-	readline.PcItem("INSERT",
-		readline.PcItem("VERTEX"),
-		readline.PcItem("EDGE"),
-	),
-	// update
-	readline.PcItem("UPDATE",
-		readline.PcItem("CONFIGS"),
-		readline.PcItem("VERTEX"),
-		readline.PcItem("EDGE"),
-	),
-	// upsert
-	readline.PcItem("UPSERT",
-		readline.PcItem("VERTEX"),
-		readline.PcItem("EDGE"),
-	),
-	// delete
-	readline.PcItem("DELETE",
-		readline.PcItem("VERTEX"),
-		readline.PcItem("EDGE"),
-	),
-
-	// grant
-	readline.PcItem("GRANT",
-		readline.PcItem("ROLE"),
-	),
-	// revoke
-	readline.PcItem("REVOKE",
-		readline.PcItem("ROLE"),
-	),
-	// change password
-	readline.PcItem("CHANGE",
-		readline.PcItem("PASSWORD"),
-	),
-)
+// The completion tree by the grammar
+var completer = readline.NewPrefixCompleter()
+
+// Prompt for the continued lines of a multi-line statement
+const continuePrompt = "...> "
+
+// Ctrl+V inserts the first value of the last result at the cursor,
+// Alt+V can't be distinguished from `v` by readline
+const keyInsertLastValue = 22
+
+// The listeners tried in order until one changes the line
+type listeners []readline.Listener
+
+func (ls listeners) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	for _, l := range ls {
+		if newLine, newPos, ok := l.OnChange(line, pos, key); ok {
+			return newLine, newPos, true
+		}
+	}
+	return nil, 0, false
+}
+
+type lastValueListener struct{}
+
+func (lastValueListener) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	// The key itself has been inserted before the cursor
+	if key != keyInsertLastValue || pos == 0 || line[pos-1] != key {
+		return nil, 0, false
+	}
+	value := []rune(lastFirstValue())
+	newLine := make([]rune, 0, len(line)+len(value))
+	newLine = append(newLine, line[:pos-1]...)
+	newLine = append(newLine, value...)
+	newLine = append(newLine, line[pos:]...)
+	return newLine, pos - 1 + len(value), true
+}
+
+// The prompt template by `--prompt`, empty for the default one
+var promptTemplate = ""
+
+// The last statement for the prompt, the error code and name are empty if succeeded
+var lastStatus struct {
+	code    string
+	name    string
+	elapsed time.Duration
+}
+
+// {name} or {name?text}, the text is shown if the value is not empty
+var promptPlaceholderPattern = regexp.MustCompile(`\{(\w+)(?:\?([^}]*))?\}`)
+
+// Expand the placeholders: user, host, space, code, err, elapsed
+func expandPrompt(template string, space string, user string) string {
+	values := map[string]string{
+		"user":  user,
+		"host":  conn.Address,
+		"space": space,
+		"code":  lastStatus.code,
+		"err":   lastStatus.name,
+	}
+	if lastStatus.elapsed > 0 {
+		values["elapsed"] = lastStatus.elapsed.Round(time.Millisecond).String()
+	}
+	return promptPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		m := promptPlaceholderPattern.FindStringSubmatch(placeholder)
+		value, ok := values[m[1]]
+		if !ok {
+			return placeholder
+		}
+		if strings.Contains(placeholder, "?") {
+			if value == "" {
+				return ""
+			}
+			return m[2]
+		}
+		return value
+	})
+}
 
 func promptString(space string, user string, isErr bool, isTTY bool) string {
 	prompt := ""
@@ -112,18 +107,39 @@ func promptString(space string, user string, isErr bool, isTTY bool) string {
 	if isTTY && isErr {
 		prompt += fmt.Sprintf("%s%s%s", ttyColorPrefix, ttyColorRed, ttyColorSuffix)
 	}
-	prompt += fmt.Sprintf("(%s@%s) [(%s)]> ", user, NebulaLabel, space)
+	if promptTemplate != "" {
+		prompt += expandPrompt(promptTemplate, space, user)
+	} else {
+		prompt += fmt.Sprintf("(%s@%s) [(%s)]> ", user, NebulaLabel, space)
+	}
 	if isTTY {
 		prompt += fmt.Sprintf("%s%s%s", ttyColorPrefix, ttyColorReset, ttyColorSuffix)
 	}
 	return prompt
 }
 
+var stdinIsTTY = readline.IsTerminal(int(os.Stdin.Fd()))
+
+// Read the password with echo disabled
+func promptPassword() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !readline.IsTerminal(fd) {
+		return "", fmt.Errorf("stdin is not a terminal, specify the password by -p")
+	}
+	fmt.Print("Enter password: ")
+	p, err := readline.ReadPassword(fd)
+	fmt.Println()
+	return string(p), err
+}
+
 type Cli interface {
 	ReadLine() (/*line*/ string, /*err*/ error, /*exit*/ bool)
 	Interactive() bool
 	SetisErr(bool)
 	SetSpace(string)
+	SetContinue(bool)
+	// Read one line with the specified prompt, e.g. the wizard questions
+	Ask(string) (/*line*/ string, /*err*/ error, /*exit*/ bool)
 }
 
 // interactive
@@ -133,25 +149,51 @@ type iCli struct {
 	space string
 	isErr bool
 	isTTY bool
+	isContinue bool
+	askPrompt string
+	// The current statement has the password, not saved to the history
+	isSecret bool
 }
 
 func NewiCli(home string, user string) *iCli {
-	r, err := readline.NewEx(&readline.Config{
+	// The stdout may be replaced by the session recording
+	isTTY := stdoutIsTTY
+	config := &readline.Config{
 			// See https://github.com/chzyer/readline/issues/169
 			Prompt:          nil,
 			HistoryFile:     path.Join(home, ".nebula_history"),
 			AutoComplete:    completer,
+			Listener:        listeners{lastValueListener{}, suggestionListener{}},
+			Painter:         nGQLPainter{isTTY},
 			InterruptPrompt: "^C",
 			EOFPrompt:       "",
 			HistorySearchFold:   true,
+			// Saved by ReadLine except the secret statements
+			DisableAutoSaveHistory: true,
 			FuncFilterInputRune: nil,
-		})
+		}
+	if !completionEnabled {
+		config.AutoComplete = nil
+	}
+	if !historyEnabled {
+		config.HistoryFile = ""
+	}
+	if sessionRecorder != nil {
+		config.Stdout = recordedOutput{sessionRecorder.terminal}
+		config.Stdin = recordedInput{os.Stdin}
+	}
+	r, err := readline.NewEx(config)
 	if err != nil {
 		log.Fatalf("Create readline failed, %s.", err.Error())
 	}
-	isTTY := readline.IsTerminal(int(os.Stdout.Fd()))
-	icli := &iCli{r, user, "", false,isTTY}
+	icli := &iCli{r, user, "", false,isTTY, false, "", false}
 	icli.input.SetPrompt(func() []rune {
+		if icli.askPrompt != "" {
+			return []rune(icli.askPrompt)
+		}
+		if icli.isContinue {
+			return []rune(continuePrompt)
+		}
 		return []rune(promptString(icli.space, icli.user, icli.isErr, icli.isTTY))
 	})
 	return icli
@@ -165,8 +207,29 @@ func (l *iCli) SetisErr(isErr bool) {
 	l.isErr = isErr
 }
 
-func (l iCli) ReadLine() (string, error, bool) {
+func (l *iCli) SetContinue(isContinue bool) {
+	l.isContinue = isContinue
+}
+
+func (l *iCli) ReadLine() (string, error, bool) {
+	return l.readLine(true)
+}
+
+// Read the statement line saved to the history, or the answer not saved
+func (l *iCli) readLine(save bool) (string, error, bool) {
+	// The background messages are printed above the prompt while waiting
+	promptWriter = l.input.Stdout()
+	releaseTerminal()
+	sessionRecorder.holdLine()
 	get, err := l.input.Readline()
+	holdTerminal()
+	promptWriter = nil
+	if !l.isContinue {
+		l.isSecret = false
+	}
+	// The continued lines of the secret statement are secret too
+	l.isSecret = l.isSecret || isSecretStatement(get)
+	sessionRecorder.releaseLine(get, l.isSecret)
 	if err == io.EOF || err == readline.ErrInterrupt {
 		// Ending not error
 		return get, nil, true
@@ -174,9 +237,19 @@ func (l iCli) ReadLine() (string, error, bool) {
 	if err != nil {
 		return get, err, true
 	}
+	if save && !l.isSecret {
+		l.input.SaveHistory(get)
+	}
 	return get, err, false
 }
 
+// The answers like y/n aren't saved to the history to not push out the statements
+func (l *iCli) Ask(prompt string) (string, error, bool) {
+	l.askPrompt = prompt
+	defer func() { l.askPrompt = "" }()
+	return l.readLine(false)
+}
+
 func (l iCli) Interactive() bool {
 	return true
 }
@@ -184,14 +257,23 @@ func (l iCli) Interactive() bool {
 // non-interactive
 type nCli struct {
 	io *bufio.Reader
+	// The number of the last line read
+	line *int
 }
 
 func NewnCli(i io.Reader) nCli {
-	return nCli{bufio.NewReader(i)}
+	return nCli{bufio.NewReader(i), new(int)}
+}
+
+func (l nCli) Line() int {
+	return *l.line
 }
 
 func (l nCli) ReadLine() (string, error, bool) {
-	s, _, e := l.io.ReadLine()
+	var s []byte
+	var e error
+	withoutTerminal(func() { s, _, e = l.io.ReadLine() })
+	*l.line++
 	if e == io.EOF {
 		return string(s), nil, true
 	}
@@ -201,6 +283,10 @@ func (l nCli) ReadLine() (string, error, bool) {
 	return string(s), e, false
 }
 
+func (l nCli) Ask(prompt string) (string, error, bool) {
+	return l.ReadLine()
+}
+
 func (l nCli) Interactive() bool {
 	return false
 }
@@ -211,4 +297,8 @@ func (l nCli) SetSpace(space string) {
 
 func (l nCli) SetisErr(isErr bool) {
 	// nothing
-}
\ No newline at end of file
+}
+
+func (l nCli) SetContinue(isContinue bool) {
+	// nothing
+}
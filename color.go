@@ -0,0 +1,85 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"math"
+	"os"
+	"regexp"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	"github.com/shylock-hg/readline"
+)
+
+// The ANSI colors of the values
+const (
+	colorGray   = "\033[90m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+	colorReset  = "\033[0m"
+)
+
+var colorModes = []string{"auto", "always", "never"}
+
+// Changed by `--color` or `:set color`, auto colors only if the stdout is a TTY
+var colorMode = "auto"
+
+var stdoutIsTTY = readline.IsTerminal(int(os.Stdout.Fd()))
+
+func colorEnabled() bool {
+	return colorMode == "always" || (colorMode == "auto" && stdoutIsTTY)
+}
+
+// The color of the value by type, empty means the default one
+func valueColor(value *common.Value) string {
+	if !colorEnabled() {
+		return ""
+	}
+	switch {
+	case value.IsSetNVal():
+		return colorGray
+	case value.IsSetFVal() && math.IsNaN(value.GetFVal()):
+		return colorGray
+	case value.IsSetIVal() || value.IsSetFVal():
+		return colorCyan
+	case value.IsSetSVal():
+		return colorGreen
+	case value.IsSetVVal() || value.IsSetEVal() || value.IsSetPVal():
+		return colorYellow
+	}
+	return ""
+}
+
+func colorize(s string, color string) string {
+	if color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// Color the value by type, and link the vertex if the terminal supports
+func decorate(s string, value *common.Value) string {
+	return colorize(hyperlink(s, vertexLink(value)), valueColor(value))
+}
+
+// Color the error message red
+func errorColor(s string) string {
+	if !colorEnabled() {
+		return s
+	}
+	return colorize(s, colorRed)
+}
+
+// The colors and the OSC 8 hyperlinks
+var ansiPattern = regexp.MustCompile("\033\\[[0-9;]*m|\033\\]8;;[^\033]*\033\\\\")
+
+// Remove the colors from the text written to the files, e.g. the tee file
+func stripColors(p []byte) []byte {
+	return ansiPattern.ReplaceAll(p, nil)
+}
@@ -0,0 +1,73 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+)
+
+// The subcommand by `nebula-console <name> [flags]`, each has its own flags
+type subcommand struct {
+	usage   string
+	summary string
+	run     func(args []string) int
+}
+
+// In the order listed by the help
+var subcommandNames = []string{"repl", "exec", "import", "export", "bench", "migrate", "doctor"}
+
+var subcommands map[string]subcommand
+
+// Initialized here to break the loop by referencing the usages in runConsole
+func init() {
+	consoleMode := func(mode string) func(args []string) int {
+		return func(args []string) int {
+			return runConsole(mode, args)
+		}
+	}
+	subcommands = map[string]subcommand{
+		"repl": {"nebula-console repl [flags]",
+			"The interactive console, the default without -e/-f", consoleMode("repl")},
+		"exec": {"nebula-console exec [flags] [-e <statements>|-f <file>|<statements>]",
+			"Execute the statements and exit, read stdin without the statements", consoleMode("exec")},
+		"import": {"nebula-console import --space <space> --tag <tag>|--edge <edge> --file <csv> [flags]",
+			"Import the CSV file into the tag or the edge", importMain},
+		"export": {"nebula-console export [flags] <sink|file> <statement>",
+			"Export the result of the statement to the file or the sink", exportMain},
+		"bench": {"nebula-console bench [flags] <statement>",
+			"Benchmark the statement and report the latency", consoleMode("bench")},
+		"migrate": {"nebula-console migrate [flags] --dir <directory>",
+			"Apply the *.ngql scripts of the directory in order, once each", migrateMain},
+		"doctor": {"nebula-console doctor [flags]",
+			"Check the terminal, locale, history, configuration and connectivity", doctorMain},
+	}
+}
+
+func setUsage(fs *flag.FlagSet, usage string) {
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s", usage)
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+}
+
+// The usage of the bare invocation, with the flags of all modes
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "Usage: nebula-console [flags]")
+	fmt.Fprintln(w, "       nebula-console <command> [flags], see `nebula-console <command> -h' for the flags of the command")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, name := range subcommandNames {
+		fmt.Fprintf(w, "  %-8s %s", name, subcommands[name].summary)
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
@@ -0,0 +1,111 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+func compareExecute(name string, stmt string) (*graph.ExecutionResponse, error) {
+	session, ok := sessions[name]
+	if !ok {
+		return nil, fmt.Errorf("Session `%s' not found", name)
+	}
+	resp, err := session.Execute(stmt)
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return nil, fmt.Errorf("Execute failed in session %s, %s", name, errorString(resp))
+	}
+	return resp, nil
+}
+
+func rowKey(row *graph.Row) string {
+	fields := make([]string, len(row.GetColumns()))
+	for i, col := range row.GetColumns() {
+		fields[i] = exactString(col)
+	}
+	return strings.Join(fields, "\x00")
+}
+
+// :compare <session A> <session B> <statement>
+// Show the rows only in A marked `<` and only in B marked `>`
+func compareCmd(client *Session, c Cli, args string) error {
+	fields := strings.SplitN(strings.TrimSpace(args), " ", 3)
+	if len(fields) != 3 {
+		return fmt.Errorf("Usage: :compare <session A> <session B> <statement>")
+	}
+	if err := confirmStatement(c, fields[2]); err != nil {
+		return err
+	}
+	a, err := compareExecute(fields[0], fields[2])
+	if err != nil {
+		return err
+	}
+	b, err := compareExecute(fields[1], fields[2])
+	if err != nil {
+		return err
+	}
+	return compareResps(a, b, fields[0], fields[1])
+}
+
+// Print the difference of the rows by each table, labeled by the names
+func compareResps(a, b *graph.ExecutionResponse, nameA, nameB string) error {
+	if len(a.GetData()) != len(b.GetData()) {
+		return fmt.Errorf("Different tables count, %d vs %d", len(a.GetData()), len(b.GetData()))
+	}
+
+	for i, tableA := range a.GetData() {
+		tableB := b.GetData()[i]
+		if strings.Join(columnNames(tableA), ",") != strings.Join(columnNames(tableB), ",") {
+			return fmt.Errorf("Different columns, (%s) vs (%s)",
+				strings.Join(columnNames(tableA), ","), strings.Join(columnNames(tableB), ","))
+		}
+		// Multiset difference of the rows
+		counts := map[string]int{}
+		for _, row := range tableB.GetRows() {
+			counts[rowKey(row)]++
+		}
+		diff := &graph.DataSet{ColumnNames: append([][]byte{[]byte("diff")}, tableA.GetColumnNames()...)}
+		onlyA := 0
+		for _, row := range tableA.GetRows() {
+			key := rowKey(row)
+			if counts[key] > 0 {
+				counts[key]--
+				continue
+			}
+			onlyA++
+			columns := append([]*common.Value{{SVal: []byte("< " + nameA)}}, row.GetColumns()...)
+			diff.Rows = append(diff.Rows, &graph.Row{Columns: columns})
+		}
+		onlyB := 0
+		for _, row := range tableB.GetRows() {
+			key := rowKey(row)
+			if counts[key] == 0 {
+				continue
+			}
+			counts[key]--
+			onlyB++
+			columns := append([]*common.Value{{SVal: []byte("> " + nameB)}}, row.GetColumns()...)
+			diff.Rows = append(diff.Rows, &graph.Row{Columns: columns})
+		}
+		if onlyA == 0 && onlyB == 0 {
+			fmt.Fprintf(out, "Identical %d rows.", len(tableA.GetRows()))
+			fmt.Fprintln(out)
+			continue
+		}
+		t.PrintTable(diff)
+		fmt.Fprintf(out, "%d rows only in %s, %d rows only in %s.", onlyA, nameA, onlyB, nameB)
+		fmt.Fprintln(out)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	readline "github.com/shylock-hg/readline"
+)
+
+// Merge the entry into the completer, the words are completed in sequence, e.g. `SUBMIT JOB COMPACT`
+func addCompletion(root readline.PrefixCompleterInterface, entry string) {
+	node := root
+	for _, word := range strings.Fields(entry) {
+		var next readline.PrefixCompleterInterface
+		for _, child := range node.GetChildren() {
+			if strings.EqualFold(strings.TrimSpace(string(child.GetName())), word) {
+				next = child
+				break
+			}
+		}
+		if next == nil {
+			next = readline.PcItem(word)
+			node.SetChildren(append(node.GetChildren(), next))
+		}
+		node = next
+	}
+}
+
+// One entry per line of the files, the lines beginning with `#` are comments
+func readCompletionFile(file string) ([]string, error) {
+	fd, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	entries := []string{}
+	scanner := bufio.NewScanner(fd)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			entries = append(entries, line)
+		}
+	}
+	return entries, scanner.Err()
+}
+
+// The directory of the plugin files
+var completionsDir = ""
+
+// Register the completions of the configuration and the plugin files in the directory, e.g. shipped
+// by the team with the snippets of their schema, the broken file is warned and skipped
+// Skip building the completion tree by `--no-completion`, e.g. the scripted interactive runs
+var completionEnabled = true
+
+func loadCompletions(c *Config) {
+	for _, entry := range c.Completions {
+		addCompletion(completer, entry)
+	}
+	if completionsDir == "" {
+		return
+	}
+	files, _ := filepath.Glob(filepath.Join(completionsDir, "*.txt"))
+	sort.Strings(files)
+	for _, file := range files {
+		entries, err := readCompletionFile(file)
+		if err != nil {
+			fmt.Printf("[WARNING] Load completions %s failed, %s", file, err.Error())
+			fmt.Println()
+			continue
+		}
+		for _, entry := range entries {
+			addCompletion(completer, entry)
+		}
+	}
+}
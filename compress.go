@@ -0,0 +1,75 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var compressModes = []string{"none", "gzip", "zstd"}
+
+var compressExtensions = map[string]string{"gzip": ".gz", "zstd": ".zst"}
+
+// Close the compressor, then the underlying writer
+type compressedWriter struct {
+	io.WriteCloser
+	underlying io.Closer
+}
+
+func (w *compressedWriter) Close() error {
+	err := w.WriteCloser.Close()
+	if e := w.underlying.Close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+// Compress the stream to the writer, zstd by the command since not in the standard library
+func compressWriter(w io.WriteCloser, mode string) (io.WriteCloser, error) {
+	switch mode {
+	case "", "none":
+		return w, nil
+	case "gzip":
+		return &compressedWriter{gzip.NewWriter(w), w}, nil
+	case "zstd":
+		cmd := exec.Command("zstd", "-q", "-c")
+		cmd.Stdout = w
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			w.Close()
+			return nil, err
+		}
+		if err = cmd.Start(); err != nil {
+			w.Close()
+			return nil, fmt.Errorf("Start zstd failed, %s", err.Error())
+		}
+		return &compressedWriter{&commandWriter{cmd, stdin}, w}, nil
+	}
+	return nil, fmt.Errorf("Unknown compression `%s'", mode)
+}
+
+// Append the extension of the compression if missing
+func compressedPath(path string, mode string) string {
+	if ext := compressExtensions[mode]; ext != "" && !strings.HasSuffix(path, ext) {
+		return path + ext
+	}
+	return path
+}
+
+func createFile(path string, mode string) (io.WriteCloser, error) {
+	fd, err := os.Create(compressedPath(path, mode))
+	if err != nil {
+		return nil, err
+	}
+	return compressWriter(fd, mode)
+}
@@ -0,0 +1,57 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+)
+
+// Output destination referenced by name, e.g. `:export mysink SHOW HOSTS`
+type Sink struct {
+	// file: write to the file `path`
+	// command: pipe to the shell command `command`, e.g. `aws s3 cp - s3://bucket/key`
+	Type     string `json:"type"`
+	Path     string `json:"path"`     // file pattern, support `{time}` and `$ENV`
+	Command  string `json:"command"`  // support `{time}` and `$ENV`
+	Format   string `json:"format"`   // csv(default), tsv or json
+	Binary   string `json:"binary"`   // the non-UTF8 strings, escape(default), base64 or replace
+	Compress string `json:"compress"` // none(default), gzip or zstd
+}
+
+// Called when the batch run or the scheduled statement failed
+type Webhook struct {
+	URL  string `json:"url"`
+	Type string `json:"type"` // generic(default) posts the JSON object, slack posts the message text
+}
+
+// The console configuration file in JSON
+type Config struct {
+	Sinks   map[string]Sink `json:"sinks"`
+	Webhook Webhook         `json:"webhook"`
+	// The extra completion entries, e.g. `MATCH (v:person) RETURN v`
+	Completions []string `json:"completions"`
+}
+
+var conf = &Config{}
+
+// Load the configuration, the missing file is treated as empty configuration
+func loadConfig(file string) (*Config, error) {
+	c := &Config{}
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(content, c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -0,0 +1,69 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Execute the destructive statements without confirmation, by `--force`
+var forceDestructive = false
+
+var (
+	dropPattern   = regexp.MustCompile(`(?i)\bDROP\s+(SPACE|TAG|EDGE)\b`)
+	deletePattern = regexp.MustCompile(`(?i)\bDELETE\s+(VERTEX|EDGE)\b`)
+	// The ids from the pipe or variable, e.g. `LOOKUP ON player | DELETE VERTEX $-.VertexID`
+	referencedIDsPattern = regexp.MustCompile(`\$(-|\w+)\.`)
+	constraintPattern    = regexp.MustCompile(`(?i)\b(WHERE|LIMIT)\b`)
+)
+
+// The destructive clause of the statement, empty if none
+// DELETE of the literal ids or the constrained input is not destructive
+func destructiveClause(stmt string) string {
+	if m := dropPattern.FindString(stmt); m != "" {
+		return strings.ToUpper(strings.Join(strings.Fields(m), " "))
+	}
+	if m := deletePattern.FindString(stmt); m != "" && referencedIDsPattern.MatchString(stmt) &&
+		!constraintPattern.MatchString(stmt) {
+		return strings.ToUpper(strings.Join(strings.Fields(m), " ")) + " without WHERE"
+	}
+	return ""
+}
+
+// Ask for the destructive statement typed interactively, returns whether to execute
+func confirmDestructive(c Cli, stmt string) bool {
+	if forceDestructive {
+		return true
+	}
+	if _, typed := c.(*iCli); !typed {
+		return true
+	}
+	clause := destructiveClause(stmt)
+	if clause == "" {
+		return true
+	}
+	answer, err, exit := c.Ask(clause + " is destructive. Are you sure? [y/N] ")
+	if err != nil || exit {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// The destructive statement declined, printed as `[CANCELLED]` instead of the error
+var errCancelled = errors.New("Cancelled")
+
+// Every console command executing the statements asks like the typed statements before executing,
+// e.g. `:async DROP SPACE s`, the background ones like `:schedule` ask when submitted
+func confirmStatement(c Cli, stmt string) error {
+	if !confirmDestructive(c, stmt) {
+		return errCancelled
+	}
+	return nil
+}
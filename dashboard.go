@@ -0,0 +1,148 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shylock-hg/readline"
+)
+
+var stderrIsTTY = readline.IsTerminal(int(os.Stderr.Fd()))
+
+// The throughput of the `-f` run with the output redirected
+var scriptProgress *dashboard
+
+const dashboardInterval = 500 * time.Millisecond
+
+// Count the bytes read for the progress
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+// The single line of the throughput updated in place on stderr, the methods of nil do nothing
+type dashboard struct {
+	mutex sync.Mutex
+	// rows or statements
+	unit     string
+	input    *countingReader
+	total    int64 // the input bytes, 0 if unknown
+	done     int64
+	errors   int64
+	inFlight int
+	start    time.Time
+	stop     chan struct{}
+	stopped  chan struct{}
+}
+
+// Returns nil if stderr is not a terminal
+func newDashboard(unit string) *dashboard {
+	if !stderrIsTTY {
+		return nil
+	}
+	d := &dashboard{unit: unit, start: time.Now(), stop: make(chan struct{}), stopped: make(chan struct{})}
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(dashboardInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				d.render()
+			}
+		}
+	}()
+	return d
+}
+
+// Track the input for the ETA, the total is known if seekable
+func (d *dashboard) track(r io.Reader) io.Reader {
+	if d == nil {
+		return r
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if s, ok := r.(io.Seeker); ok {
+		if current, err := s.Seek(0, io.SeekCurrent); err == nil {
+			if end, err := s.Seek(0, io.SeekEnd); err == nil {
+				d.total = end - current
+			}
+			s.Seek(current, io.SeekStart)
+		}
+	}
+	d.input = &countingReader{r: r}
+	return d.input
+}
+
+func (d *dashboard) begin() {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.inFlight++
+}
+
+func (d *dashboard) end(done int, errors int) {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.inFlight--
+	d.done += int64(done)
+	d.errors += int64(errors)
+}
+
+func (d *dashboard) render() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	elapsed := time.Since(d.start)
+	line := fmt.Sprintf("%d %s, %.0f %s/s, %d in flight, %d errors, %s elapsed", d.done, d.unit,
+		float64(d.done)/elapsed.Seconds(), d.unit, d.inFlight, d.errors, elapsed.Round(time.Second))
+	if d.input != nil && d.total > 0 {
+		read := atomic.LoadInt64(&d.input.n)
+		if read > 0 && read <= d.total {
+			eta := time.Duration(float64(elapsed) * float64(d.total-read) / float64(read))
+			line += fmt.Sprintf(", %d%%, ETA %s", read*100/d.total, eta.Round(time.Second))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+}
+
+// Erase the line before printing the other messages, redrawn by the next tick
+func (d *dashboard) clear() {
+	if d == nil {
+		return
+	}
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	fmt.Fprint(os.Stderr, "\r\033[K")
+}
+
+func (d *dashboard) close() {
+	if d == nil {
+		return
+	}
+	close(d.stop)
+	<-d.stopped
+	d.clear()
+}
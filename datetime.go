@@ -0,0 +1,63 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+)
+
+// Convert the datetime values to the zone, nil keeps the zone from the server
+var displayLocation *time.Location
+
+// The datetime in ISO-8601 with microseconds, e.g. 2020-09-01T08:00:00.000000Z
+const dateTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+
+func formatDate(date *common.Date) string {
+	return fmt.Sprintf("%04d-%02d-%02d", date.GetYear(), date.GetMonth(), date.GetDay())
+}
+
+// The fields of the server datetime are in UTC, and the timezone offset in seconds is the zone to show it,
+// e.g. 00:00:00 with the offset 28800 is 08:00:00+08:00, then converted to the location if not nil
+func formatDateTime(datetime *common.DateTime, location *time.Location) string {
+	t := time.Date(int(datetime.GetYear()), time.Month(datetime.GetMonth()), int(datetime.GetDay()),
+		int(datetime.GetHour()), int(datetime.GetMinute()), int(datetime.GetSec()),
+		int(datetime.GetMicrosec())*int(time.Microsecond), time.UTC)
+	if offset := int(datetime.GetTimezone()); offset != 0 {
+		t = t.In(time.FixedZone("", offset))
+	}
+	if location != nil {
+		t = t.In(location)
+	}
+	return t.Format(dateTimeLayout)
+}
+
+// The `timezone` setting, empty keeps the zone from the server
+func timezoneSetting() setting {
+	return setting{
+		func() string {
+			if displayLocation == nil {
+				return ""
+			}
+			return displayLocation.String()
+		},
+		func(s string) error {
+			if s == "" || s == "server" {
+				displayLocation = nil
+				return nil
+			}
+			loc, err := time.LoadLocation(s)
+			if err != nil {
+				return fmt.Errorf("Unknown timezone `%s', expect the IANA name like Asia/Shanghai, UTC or Local", s)
+			}
+			displayLocation = loc
+			return nil
+		},
+	}
+}
@@ -0,0 +1,251 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shylock-hg/readline"
+)
+
+const doctorDialTimeout = 3 * time.Second
+
+// The findings of `nebula-console doctor`, the problems are followed by the fix
+type doctor struct {
+	warnings int
+	failures int
+}
+
+func (d *doctor) ok(format string, args ...interface{}) {
+	fmt.Printf("[OK] %s", fmt.Sprintf(format, args...))
+	fmt.Println()
+}
+
+func (d *doctor) warn(fix string, format string, args ...interface{}) {
+	d.warnings++
+	fmt.Printf("[WARN] %s", fmt.Sprintf(format, args...))
+	fmt.Println()
+	fmt.Printf("  Fix: %s", fix)
+	fmt.Println()
+}
+
+func (d *doctor) fail(fix string, format string, args ...interface{}) {
+	d.failures++
+	fmt.Printf("[FAIL] %s", fmt.Sprintf(format, args...))
+	fmt.Println()
+	fmt.Printf("  Fix: %s", fix)
+	fmt.Println()
+}
+
+func (d *doctor) checkTerminal() {
+	if !stdinIsTTY || !stdoutIsTTY {
+		d.warn("Run the console in a terminal for the interactive mode, the redirected stdin/stdout are for -e/-f and the piped statements",
+			"Terminal: stdin is terminal %t, stdout is terminal %t", stdinIsTTY, stdoutIsTTY)
+		return
+	}
+	term := os.Getenv("TERM")
+	if runtime.GOOS != "windows" && (term == "" || term == "dumb") {
+		d.warn("export TERM=xterm-256color, the line editing and colors need a capable terminal",
+			"Terminal: TERM is `%s'", term)
+		return
+	}
+	if width := readline.GetScreenWidth(); width > 0 && width < 80 {
+		d.warn("Widen the terminal window, or `:format vertical' for the wide results",
+			"Terminal: width %d is less than 80 columns", width)
+		return
+	}
+	d.ok("Terminal: TERM `%s', width %d", term, readline.GetScreenWidth())
+}
+
+func (d *doctor) checkLocale() {
+	if runtime.GOOS == "windows" {
+		d.ok("Locale: skipped on Windows, run `chcp 65001' if the non-ASCII strings are garbled")
+		return
+	}
+	// The first one set takes effect
+	name, value := "LANG", ""
+	for _, env := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if value = os.Getenv(env); value != "" {
+			name = env
+			break
+		}
+	}
+	lower := strings.ToLower(value)
+	if !strings.Contains(lower, "utf-8") && !strings.Contains(lower, "utf8") {
+		d.warn("export LANG=en_US.UTF-8 (or another UTF-8 locale listed by `locale -a'), the non-ASCII strings may be garbled",
+			"Locale: %s is `%s', not UTF-8", name, value)
+		return
+	}
+	d.ok("Locale: %s is `%s'", name, value)
+}
+
+// The file is writable or could be created, and not readable by others since the statements may contain secrets
+func (d *doctor) checkHistoryFile(file string) {
+	info, err := os.Stat(file)
+	if os.IsNotExist(err) {
+		f, err := ioutil.TempFile(filepath.Dir(file), ".nebula_doctor")
+		if err != nil {
+			d.fail("Make the directory writable, or set HOME to a writable directory",
+				"History: %s doesn't exist and can't be created, %s", file, err.Error())
+			return
+		}
+		f.Close()
+		os.Remove(f.Name())
+		d.ok("History: %s will be created", file)
+		return
+	}
+	if err != nil {
+		d.fail("Check the permissions of the directory", "History: %s, %s", file, err.Error())
+		return
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		d.fail(fmt.Sprintf("chmod u+rw %s, or chown it to the current user", file),
+			"History: %s is not writable, %s", file, err.Error())
+		return
+	}
+	f.Close()
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		d.warn(fmt.Sprintf("chmod 600 %s", file),
+			"History: %s is accessible by the others (%s), the statements may contain the passwords", file, info.Mode().Perm())
+		return
+	}
+	d.ok("History: %s", file)
+}
+
+// The JSON syntax, and the values of the sinks and the webhook
+func (d *doctor) checkConfig(file string) {
+	c, err := loadConfig(file)
+	if err != nil {
+		d.fail(fmt.Sprintf("Fix the JSON of %s, e.g. by `python -m json.tool %s'", file, file),
+			"Config: %s is invalid, %s", file, err.Error())
+		return
+	}
+	if _, err = os.Stat(file); os.IsNotExist(err) {
+		d.ok("Config: %s doesn't exist, the defaults are used", file)
+		return
+	}
+	problems := 0
+	names := make([]string, 0, len(c.Sinks))
+	for name := range c.Sinks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sink := c.Sinks[name]
+		var problem, fix string
+		switch {
+		case sink.Type != "" && sink.Type != "file" && sink.Type != "command":
+			problem, fix = fmt.Sprintf("unknown type `%s'", sink.Type), "Set the type to file or command"
+		case (sink.Type == "" || sink.Type == "file") && sink.Path == "":
+			problem, fix = "missing path", "Set the path of the file sink, e.g. /tmp/export-{time}.csv"
+		case sink.Type == "command" && sink.Command == "":
+			problem, fix = "missing command", "Set the command of the command sink, e.g. `aws s3 cp - s3://bucket/key'"
+		case sink.Format != "" && sink.Format != "csv" && sink.Format != "json":
+			problem, fix = fmt.Sprintf("unknown format `%s'", sink.Format), "Set the format to csv or json"
+		case sink.Binary != "" && !contains(binaryModes, sink.Binary):
+			problem, fix = fmt.Sprintf("unknown binary mode `%s'", sink.Binary), "Set the binary to "+strings.Join(binaryModes, ", ")
+		case sink.Compress != "" && !contains(compressModes, sink.Compress):
+			problem, fix = fmt.Sprintf("unknown compression `%s'", sink.Compress), "Set the compress to "+strings.Join(compressModes, ", ")
+		default:
+			continue
+		}
+		problems++
+		d.fail(fix, "Config: sink `%s' of %s, %s", name, file, problem)
+	}
+	if t := c.Webhook.Type; t != "" && t != "generic" && t != "slack" {
+		problems++
+		d.fail("Set the webhook type to generic or slack", "Config: unknown webhook type `%s' of %s", t, file)
+	}
+	if problems == 0 {
+		d.ok("Config: %s, %d sinks", file, len(c.Sinks))
+	}
+}
+
+// Dial the address by TCP, the authentication is not checked since the passwords are not saved
+func (d *doctor) checkAddress(profile string, address string) {
+	start := time.Now()
+	c, err := net.DialTimeout("tcp", address, doctorDialTimeout)
+	if err != nil {
+		d.fail("Check graphd is running and listening on the address, and the firewall allows the port",
+			"Connect: %s unreachable, %s", profile, err.Error())
+		return
+	}
+	c.Close()
+	d.ok("Connect: %s reachable in %s", profile, time.Since(start).Round(time.Millisecond))
+}
+
+// The profiles `user@address' used before by the state file
+func (d *doctor) checkProfiles(file string, address string) {
+	content, err := ioutil.ReadFile(file)
+	s := &State{}
+	if err == nil {
+		if err = json.Unmarshal(content, s); err != nil {
+			d.warn(fmt.Sprintf("Remove %s, it only remembers the last space of each profile", file),
+				"State: %s is invalid, %s", file, err.Error())
+		}
+	}
+	profiles := make([]string, 0, len(s.Spaces))
+	for profile := range s.Spaces {
+		profiles = append(profiles, profile)
+	}
+	sort.Strings(profiles)
+	d.checkAddress(address, address)
+	checked := map[string]bool{address: true}
+	for _, profile := range profiles {
+		i := strings.LastIndex(profile, "@")
+		if checked[profile[i+1:]] {
+			continue
+		}
+		checked[profile[i+1:]] = true
+		d.checkAddress(profile, profile[i+1:])
+	}
+}
+
+// nebula-console doctor [--address <ip> --port <port>] [--config <file>]
+func doctorMain(args []string) int {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	setUsage(fs, subcommands["doctor"].usage)
+	address := fs.String("address", "127.0.0.1", "The Nebula Graph IP address to check besides the profiles used before")
+	port := fs.Int("port", 3699, "The Nebula Graph Port")
+	configFile := fs.String("config", "", "The console configuration file, default ~/.nebula_console.json")
+	fs.Parse(args)
+
+	home, err := consoleHome()
+	if err != nil {
+		log.Fatalf("%s", err.Error())
+	}
+	if *configFile == "" {
+		*configFile = filepath.Join(home, ".nebula_console.json")
+	}
+	d := &doctor{}
+	d.checkTerminal()
+	d.checkLocale()
+	d.checkHistoryFile(filepath.Join(home, ".nebula_history"))
+	d.checkHistoryFile(filepath.Join(home, ".nebula_history_statements"))
+	d.checkConfig(*configFile)
+	d.checkProfiles(filepath.Join(home, ".nebula_console_state.json"), fmt.Sprintf("%s:%d", *address, *port))
+
+	fmt.Println()
+	fmt.Printf("%d failures, %d warnings.", d.failures, d.warnings)
+	fmt.Println()
+	if d.failures > 0 {
+		return 1
+	}
+	return 0
+}
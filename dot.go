@@ -0,0 +1,111 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The vertices and edges collected from the result, deduplicated in order
+type dotGraph struct {
+	nodes   []string
+	nodeSet map[string]bool
+	edges   []string
+	edgeSet map[string]bool
+	// The source and destination of the edges, in the same order
+	links [][2]string
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func (g *dotGraph) addNode(vid string) {
+	if !g.nodeSet[vid] {
+		g.nodeSet[vid] = true
+		g.nodes = append(g.nodes, vid)
+	}
+}
+
+// The negative edge type means the reversed direction
+func (g *dotGraph) addEdge(src string, dst string, typ int32, name string, rank int64) {
+	if typ < 0 {
+		src, dst = dst, src
+	}
+	g.addNode(src)
+	g.addNode(dst)
+	edge := fmt.Sprintf("%s -> %s [label=%s]", dotQuote(src), dotQuote(dst), dotQuote(fmt.Sprintf("%s@%d", name, rank)))
+	if !g.edgeSet[edge] {
+		g.edgeSet[edge] = true
+		g.edges = append(g.edges, edge)
+		g.links = append(g.links, [2]string{src, dst})
+	}
+}
+
+func (g *dotGraph) addValue(value *common.Value) {
+	switch {
+	case value.IsSetVVal():
+		g.addNode(string(value.GetVVal().GetVid()))
+	case value.IsSetEVal():
+		edge := value.GetEVal()
+		g.addEdge(string(edge.GetSrc()), string(edge.GetDst()), int32(edge.GetType()), string(edge.GetName()),
+			int64(edge.GetRanking()))
+	case value.IsSetPVal():
+		path := value.GetPVal()
+		src := string(path.GetSrc().GetVid())
+		g.addNode(src)
+		for _, step := range path.GetSteps() {
+			dst := string(step.GetDst().GetVid())
+			g.addEdge(src, dst, int32(step.GetType()), string(step.GetName()), int64(step.GetRanking()))
+			src = dst
+		}
+	case value.IsSetLVal():
+		for _, v := range value.GetLVal().GetValues() {
+			g.addValue(v)
+		}
+	case value.IsSetUVal():
+		for _, v := range value.GetUVal().GetValues() {
+			g.addValue(v)
+		}
+	}
+}
+
+func newDotGraph() *dotGraph {
+	return &dotGraph{nodeSet: map[string]bool{}, edgeSet: map[string]bool{}}
+}
+
+func (g *dotGraph) addTable(table *graph.DataSet) {
+	for _, row := range table.GetRows() {
+		for _, col := range row.GetColumns() {
+			g.addValue(col)
+		}
+	}
+}
+
+// Print the vertices, edges and paths as a DOT digraph, e.g. piped to `dot -Tpng`
+func (t Table) PrintDot(table *graph.DataSet) {
+	g := newDotGraph()
+	g.addTable(table)
+	g.print()
+}
+
+func (g *dotGraph) print() {
+	fmt.Fprintln(out, "digraph result {")
+	for _, node := range g.nodes {
+		fmt.Fprintf(out, "  %s;", dotQuote(node))
+		fmt.Fprintln(out)
+	}
+	for _, edge := range g.edges {
+		fmt.Fprintf(out, "  %s;", edge)
+		fmt.Fprintln(out)
+	}
+	fmt.Fprintln(out, "}")
+}
@@ -0,0 +1,54 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+func isEditCommand(line string) bool {
+	plain := strings.TrimSpace(line)
+	return plain == `\e` || strings.ToLower(plain) == ":edit" || strings.HasPrefix(strings.ToLower(plain), ":edit ")
+}
+
+// :edit [statement] or \e, compose in $EDITOR from the statement or the last one, then execute it
+func editCmd(client *Session, c Cli, args string) error {
+	content := strings.TrimSpace(args)
+	if content == "" && len(stmtHistory.entries) > 0 {
+		content = stmtHistory.entries[len(stmtHistory.entries)-1]
+	}
+	tmp, err := ioutil.TempFile("", "nebula-edit-*.ngql")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.WriteString(content + "\n")
+	tmp.Close()
+	if err != nil {
+		return err
+	}
+	if err = runTerminalCommand(fmt.Sprintf("%s %s", editorCommand(), tmp.Name()), os.Stdin); err != nil {
+		return fmt.Errorf("Editor `%s' failed, %s", editorCommand(), err.Error())
+	}
+	edited, err := ioutil.ReadFile(tmp.Name())
+	if err != nil {
+		return err
+	}
+	stmt := strings.TrimSpace(string(edited))
+	if stmt == "" {
+		fmt.Println("Nothing to execute.")
+		return nil
+	}
+	fmt.Println(stmt)
+	if _, typed := c.(*iCli); typed {
+		stmtHistory.record(stmt)
+	}
+	return execute(client, c, stmt)
+}
@@ -0,0 +1,128 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// The named snapshots of the interactive setup, under the home directory
+var envDir = ""
+
+type envSnapshot struct {
+	Space     string            `json:"space"`
+	Format    string            `json:"format"`
+	Settings  map[string]string `json:"settings"`  // `:set` options
+	Variables map[string]string `json:"variables"` // `:set` variables and `--param`
+}
+
+var envNamePattern = regexp.MustCompile(`^[\w.-]+$`)
+
+func envFile(name string) (string, error) {
+	if !envNamePattern.MatchString(name) {
+		return "", fmt.Errorf("Invalid snapshot name `%s', expect letters, digits, `_', `-' or `.'", name)
+	}
+	return filepath.Join(envDir, name+".json"), nil
+}
+
+func saveEnv(client *Session, name string) error {
+	file, err := envFile(name)
+	if err != nil {
+		return err
+	}
+	snapshot := envSnapshot{client.space, outputFormat, map[string]string{}, map[string]string{}}
+	for name, s := range settings {
+		snapshot.Settings[name] = s.get()
+	}
+	for name, value := range variables {
+		snapshot.Variables[name] = value
+	}
+	content, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(envDir, 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, content, 0600)
+}
+
+func loadEnv(client *Session, c Cli, name string) error {
+	file, err := envFile(name)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	snapshot := envSnapshot{}
+	if err = json.Unmarshal(content, &snapshot); err != nil {
+		return fmt.Errorf("Parse %s failed, %s", file, err.Error())
+	}
+	for name, value := range snapshot.Settings {
+		s, ok := settings[name]
+		if !ok {
+			continue
+		}
+		if err = s.set(value); err != nil {
+			fmt.Printf("[WARNING] Restore `%s' failed, %s", name, err.Error())
+			fmt.Println()
+		}
+	}
+	variables = map[string]string{}
+	for name, value := range snapshot.Variables {
+		variables[name] = value
+	}
+	if snapshot.Format != "" {
+		if err = formatCmd(client, c, snapshot.Format); err != nil {
+			return err
+		}
+	}
+	if snapshot.Space != "" && snapshot.Space != client.space {
+		return execute(client, c, "USE "+quoteName(snapshot.Space))
+	}
+	return nil
+}
+
+// :env save|load <name>, or :env list
+func envCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 1 && fields[0] == "list" {
+		files, err := filepath.Glob(filepath.Join(envDir, "*.json"))
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			fmt.Println(strings.TrimSuffix(filepath.Base(file), ".json"))
+		}
+		return nil
+	}
+	if len(fields) != 2 {
+		return fmt.Errorf("Usage: :env save|load <name>, or :env list")
+	}
+	switch fields[0] {
+	case "save":
+		if err := saveEnv(client, fields[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Saved the environment `%s'.", fields[1])
+		fmt.Println()
+		return nil
+	case "load":
+		return loadEnv(client, c, fields[1])
+	}
+	return fmt.Errorf("Usage: :env save|load <name>, or :env list")
+}
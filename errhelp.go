@@ -0,0 +1,180 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+type errorHelp struct {
+	meaning     string
+	remediation []string
+}
+
+// The error codes by the symbolic name
+var errorCatalog = map[string]errorHelp{
+	"E_DISCONNECTED": {
+		"The connection to graphd was closed.",
+		[]string{"Check graphd is running and reachable", "Reconnect or restart the console"},
+	},
+	"E_FAIL_TO_CONNECT": {
+		"Failed to connect to graphd or the storage/meta services from graphd.",
+		[]string{"Check the address and port", "Check the firewall and `SHOW HOSTS` for the offline hosts"},
+	},
+	"E_RPC_FAILURE": {
+		"The RPC between the services failed, e.g. timeout or the storaged is down.",
+		[]string{"Check `SHOW HOSTS` for the offline storaged", "Increase the storage_client_timeout_ms of graphd for the heavy queries"},
+	},
+	"E_LEADER_CHANGED": {
+		"The partition leader changed during the request.",
+		[]string{"Retry the statement", "Check `SHOW HOSTS` for the leader distribution, `BALANCE LEADER` if skewed"},
+	},
+	"E_BAD_USERNAME_PASSWORD": {
+		"The username or password is wrong.",
+		[]string{"Check the -u/-p options", "Check the user exists by `SHOW USERS` with the God role"},
+	},
+	"E_SESSION_INVALID": {
+		"The session doesn't exist, e.g. graphd restarted.",
+		[]string{"Reconnect, the console retries once automatically"},
+	},
+	"E_SESSION_TIMEOUT": {
+		"The session expired after idle for session_idle_timeout_secs.",
+		[]string{"Reconnect, the console retries once automatically", "Increase session_idle_timeout_secs of graphd"},
+	},
+	"E_SYNTAX_ERROR": {
+		"The statement is not valid nGQL.",
+		[]string{"Check the position reported in the message", "Quote the keywords used as names by backticks",
+			"Quote the string vertex ids, see `:quote`"},
+	},
+	"E_EXECUTION_ERROR": {
+		"The statement failed during execution, e.g. the schema or space not found.",
+		[]string{"Check the message for the cause", "`USE <space>` before the statements of data",
+			"Wait 2 heartbeats (20s by default) after the schema changes"},
+	},
+	"E_STATEMENT_EMTPY": {
+		"The statement is empty.",
+		[]string{"Remove the extra `;`", "The comment-only lines are skipped by the console"},
+	},
+	"E_STATEMENT_EMPTY": {
+		"The statement is empty.",
+		[]string{"Remove the extra `;`", "The comment-only lines are skipped by the console"},
+	},
+	"E_USER_NOT_FOUND": {
+		"The user doesn't exist.",
+		[]string{"Check the user by `SHOW USERS`", "Create the user by `CREATE USER`"},
+	},
+	"E_BAD_PERMISSION": {
+		"The user has no permission for the statement.",
+		[]string{"Check the roles by `SHOW ROLES IN <space>`", "Grant the role by `GRANT ROLE <role> ON <space> TO <user>`"},
+	},
+	"E_SEMANTIC_ERROR": {
+		"The statement is syntactically valid but semantically wrong, e.g. the unknown property or type mismatch.",
+		[]string{"Check the schema by `DESCRIBE TAG/EDGE`", "Check the variables and the YIELD columns referenced"},
+	},
+	"E_TOO_MANY_CONNECTIONS": {
+		"The connections exceed max_allowed_connections of graphd.",
+		[]string{"Close the idle consoles and clients", "Increase max_allowed_connections of graphd"},
+	},
+	"E_PARTIAL_SUCCEEDED": {
+		"The statement succeeded on part of the partitions only.",
+		[]string{"Check `SHOW HOSTS` for the offline storaged", "Retry the statement, the writes are idempotent by key"},
+	},
+	"E_SPACE_NOT_FOUND": {
+		"The space doesn't exist.",
+		[]string{"Check the spaces by `SHOW SPACES`", "Wait 2 heartbeats after `CREATE SPACE`"},
+	},
+	"E_TAG_NOT_FOUND": {
+		"The tag doesn't exist in the space.",
+		[]string{"Check the tags by `SHOW TAGS`", "Wait 2 heartbeats after `CREATE TAG`"},
+	},
+	"E_EDGE_NOT_FOUND": {
+		"The edge type doesn't exist in the space.",
+		[]string{"Check the edge types by `SHOW EDGES`", "Wait 2 heartbeats after `CREATE EDGE`"},
+	},
+	"E_INDEX_NOT_FOUND": {
+		"The index doesn't exist, e.g. LOOKUP without the index.",
+		[]string{"Check the indexes by `SHOW TAG/EDGE INDEXES`", "Create and `REBUILD` the index, see `:template run index-coverage`"},
+	},
+}
+
+// The codes unified in the later versions, e.g. -1005, not in the enum of this client
+var unifiedErrorCodes = map[int64]string{
+	-4:    "E_LEADER_CHANGED",
+	-5:    "E_SPACE_NOT_FOUND",
+	-6:    "E_TAG_NOT_FOUND",
+	-7:    "E_EDGE_NOT_FOUND",
+	-8:    "E_INDEX_NOT_FOUND",
+	-18:   "E_USER_NOT_FOUND",
+	-1001: "E_BAD_USERNAME_PASSWORD",
+	-1002: "E_SESSION_INVALID",
+	-1003: "E_SESSION_TIMEOUT",
+	-1004: "E_SYNTAX_ERROR",
+	-1005: "E_EXECUTION_ERROR",
+	-1006: "E_STATEMENT_EMPTY",
+	-1008: "E_BAD_PERMISSION",
+	-1009: "E_SEMANTIC_ERROR",
+	-1010: "E_TOO_MANY_CONNECTIONS",
+	-1011: "E_PARTIAL_SUCCEEDED",
+}
+
+func printErrorHelp(name string, code string) {
+	help := errorCatalog[name]
+	fmt.Printf("%s (%s)", name, code)
+	fmt.Println()
+	fmt.Printf("  %s", help.meaning)
+	fmt.Println()
+	for _, step := range help.remediation {
+		fmt.Printf("  - %s", step)
+		fmt.Println()
+	}
+}
+
+// :errhelp <code|name>
+func errhelpCmd(client *Session, c Cli, args string) error {
+	arg := strings.TrimSpace(args)
+	if arg == "" {
+		return fmt.Errorf("Usage: :errhelp <code|name>, e.g. :errhelp -1005")
+	}
+	n, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		// The symbolic name, e.g. E_SYNTAX_ERROR
+		name := strings.ToUpper(arg)
+		if _, ok := errorCatalog[name]; !ok {
+			return fmt.Errorf("Unknown error `%s'", arg)
+		}
+		code := "unified codes"
+		if value, ok := graph.ErrorCodeToValue[name]; ok {
+			code = strconv.FormatInt(int64(value), 10)
+		}
+		printErrorHelp(name, code)
+		return nil
+	}
+	found := false
+	// The code of this client's protocol first, then the unified one if different
+	if name, ok := graph.ErrorCodeToName[graph.ErrorCode(n)]; ok {
+		if _, ok = errorCatalog[name]; ok {
+			printErrorHelp(name, arg)
+			found = true
+		}
+	}
+	if name, ok := unifiedErrorCodes[n]; ok && (!found || graph.ErrorCodeToName[graph.ErrorCode(n)] != name) {
+		if found {
+			fmt.Println()
+			fmt.Println("In the servers with the unified error codes:")
+		}
+		printErrorHelp(name, arg)
+		found = true
+	}
+	if !found {
+		return fmt.Errorf("Unknown error code %d", n)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The subgraph accumulated by `:expand`, rendered by `:viz`
+var subgraphView = newDotGraph()
+
+// :expand <vid> [edge_type] [depth]
+// Fetch the neighborhood of the vertex in both directions and append to the subgraph view
+func expandCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 3 {
+		return fmt.Errorf("Usage: :expand <vid> [edge_type] [depth]")
+	}
+	vid, edge, depth := fields[0], "", 1
+	for _, field := range fields[1:] {
+		if n, err := strconv.Atoi(field); err == nil {
+			if n <= 0 {
+				return fmt.Errorf("Invalid depth `%s'", field)
+			}
+			depth = n
+		} else if edge == "" {
+			edge = field
+		} else {
+			return fmt.Errorf("Usage: :expand <vid> [edge_type] [depth]")
+		}
+	}
+	if !isQuoted(vid) && !isIntVidSpace(client) {
+		vid = quoteVid(vid)
+	}
+	stmt := fmt.Sprintf("GET SUBGRAPH %d STEPS FROM %s", depth, vid)
+	if edge != "" {
+		stmt += fmt.Sprintf(" BOTH %s", edge)
+	}
+	resp, err := client.Execute(stmt)
+	if err != nil {
+		return err
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return fmt.Errorf("Execute `%s' failed, %s", stmt, errorString(resp))
+	}
+	nodes, edges := len(subgraphView.nodes), len(subgraphView.links)
+	for _, table := range resp.GetData() {
+		subgraphView.addTable(table)
+	}
+	fmt.Printf("Expanded %s by %d nodes and %d edges, %d nodes and %d edges in the view, render by `:viz'.", vid,
+		len(subgraphView.nodes)-nodes, len(subgraphView.links)-edges, len(subgraphView.nodes), len(subgraphView.links))
+	fmt.Println()
+	return nil
+}
+
+// :viz [reset]
+// Print the subgraph view as DOT, or clear it
+func vizCmd(client *Session, c Cli, args string) error {
+	switch strings.TrimSpace(args) {
+	case "":
+		subgraphView.print()
+	case "reset":
+		subgraphView = newDotGraph()
+	default:
+		return fmt.Errorf("Usage: :viz [reset]")
+	}
+	return nil
+}
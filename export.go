@@ -0,0 +1,489 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Replace the placeholders and environment variables in the destination
+func expandDestination(dest string) string {
+	dest = strings.Replace(dest, "{time}", time.Now().Format("20060102150405"), -1)
+	return os.ExpandEnv(dest)
+}
+
+// The shell command sink, closing waits the command finished
+type commandWriter struct {
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func (w *commandWriter) Write(p []byte) (int, error) {
+	return w.stdin.Write(p)
+}
+
+func (w *commandWriter) Close() error {
+	if err := w.stdin.Close(); err != nil {
+		return err
+	}
+	return w.cmd.Wait()
+}
+
+func openSink(sink Sink) (io.WriteCloser, error) {
+	switch sink.Type {
+	case "", "file":
+		return createFile(expandDestination(sink.Path), sink.Compress)
+	case "command":
+		cmd := exec.Command("sh", "-c", expandDestination(sink.Command))
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		stdin, err := cmd.StdinPipe()
+		if err != nil {
+			return nil, err
+		}
+		if err = cmd.Start(); err != nil {
+			return nil, err
+		}
+		return compressWriter(&commandWriter{cmd, stdin}, sink.Compress)
+	}
+	return nil, fmt.Errorf("Unknown sink type `%s'", sink.Type)
+}
+
+// Raw string value instead of the quoted one in table
+func exportValue(value *common.Value) string {
+	if value.IsSetSVal() {
+		return string(value.GetSVal())
+	}
+	return exactString(value)
+}
+
+// The options of `:export`, overriding the sink configuration
+type exportOptions struct {
+	// How to write the non-UTF8 strings, escape(`\xNN`), base64 or replace(U+FFFD)
+	binary string
+	// Split the file into the numbered parts by the rows or the bytes, 0 means no limit
+	splitRows int
+	splitSize int64
+	// none, gzip or zstd
+	compress string
+	// Export the random rows by the fraction or the count, reproducible by the seed
+	sample     float64
+	sampleRows int
+	sampleSeed int64
+	// The anonymize mode by column, hash or redact
+	anonymize map[string]string
+	// The key of the hash mode, random by export if empty
+	anonymizeKey string
+	// The key columns of the canonical export, nil means not canonical
+	canonical []string
+}
+
+// Escape the invalid bytes as `\xNN` and the backslash as `\\`, keep the valid runes
+func escapeBinary(b []byte) string {
+	var sb strings.Builder
+	for len(b) > 0 {
+		r, size := utf8.DecodeRune(b)
+		if r == utf8.RuneError && size == 1 {
+			fmt.Fprintf(&sb, "\\x%02x", b[0])
+		} else if r == '\\' {
+			sb.WriteString("\\\\")
+		} else {
+			sb.Write(b[:size])
+		}
+		b = b[size:]
+	}
+	return sb.String()
+}
+
+// The exported value, the non-UTF8 strings are converted by the binary option
+func (opts exportOptions) value(value *common.Value) string {
+	if !value.IsSetSVal() || utf8.Valid(value.GetSVal()) {
+		return exportValue(value)
+	}
+	switch opts.binary {
+	case "base64":
+		return base64.StdEncoding.EncodeToString(value.GetSVal())
+	case "replace":
+		return strings.ToValidUTF8(string(value.GetSVal()), "\uFFFD")
+	}
+	return escapeBinary(value.GetSVal())
+}
+
+func writeCSV(w io.Writer, table *graph.DataSet, opts exportOptions) error {
+	cw := csv.NewWriter(w)
+	header := columnNames(table)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for n, row := range table.GetRows() {
+		// Not aligned with the header otherwise
+		if len(row.GetColumns()) != len(header) {
+			return fmt.Errorf("Row %d has %d columns, expect %d of the header", n+1, len(row.GetColumns()), len(header))
+		}
+		record := make([]string, len(header))
+		for i, col := range row.GetColumns() {
+			record[i] = opts.value(col)
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func jsonRows(table *graph.DataSet, opts exportOptions) []map[string]string {
+	rows := make([]map[string]string, 0, len(table.GetRows()))
+	for _, row := range table.GetRows() {
+		record := make(map[string]string, len(table.GetColumnNames()))
+		for i, col := range row.GetColumns() {
+			if i < len(table.GetColumnNames()) {
+				record[string(table.GetColumnNames()[i])] = opts.value(col)
+			}
+		}
+		rows = append(rows, record)
+	}
+	return rows
+}
+
+// One JSON array of objects for each table
+func writeJSON(w io.Writer, table *graph.DataSet, opts exportOptions) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonRows(table, opts))
+}
+
+func writeTable(w io.Writer, table *graph.DataSet, format string, opts exportOptions) error {
+	switch format {
+	case "", "csv":
+		return writeCSV(w, table, opts)
+	case "json":
+		return writeJSON(w, table, opts)
+	case "tsv":
+		return writeTSV(w, table, opts, nil)
+	}
+	return fmt.Errorf("Unknown export format `%s'", format)
+}
+
+// Prepend the `result` column of the 1-based index of the table
+func withResultColumn(table *graph.DataSet, n int) *graph.DataSet {
+	labeled := &graph.DataSet{ColumnNames: append([][]byte{[]byte("result")}, table.GetColumnNames()...)}
+	for _, row := range table.GetRows() {
+		columns := append([]*common.Value{int64Value(int64(n))}, row.GetColumns()...)
+		labeled.Rows = append(labeled.Rows, &graph.Row{Columns: columns})
+	}
+	return labeled
+}
+
+// The multiple tables are distinguished, by the `result` column and the blank line between them of CSV and TSV,
+// or the array of the tables of JSON
+func writeTables(w io.Writer, tables []*graph.DataSet, format string, opts exportOptions) error {
+	switch len(tables) {
+	case 0:
+		return nil
+	case 1:
+		return writeTable(w, tables[0], format, opts)
+	}
+	switch format {
+	case "", "csv", "tsv":
+		for i, table := range tables {
+			if i > 0 {
+				if _, err := io.WriteString(w, "\n"); err != nil {
+					return err
+				}
+			}
+			if err := writeTable(w, withResultColumn(table, i+1), format, opts); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		results := make([][]map[string]string, 0, len(tables))
+		for _, table := range tables {
+			results = append(results, jsonRows(table, opts))
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	return fmt.Errorf("Unknown export format `%s'", format)
+}
+
+var binaryModes = []string{"escape", "base64", "replace"}
+
+// Parse the leading `--name value` options of `:export`, return the rest arguments
+func parseExportOptions(args string) (exportOptions, string, error) {
+	opts := exportOptions{sampleSeed: defaultSampleSeed}
+	args = strings.TrimSpace(args)
+	for strings.HasPrefix(args, "--") {
+		fields := strings.SplitN(args, " ", 3)
+		if len(fields) < 3 {
+			return opts, "", fmt.Errorf("Missing value of option `%s'", fields[0])
+		}
+		if err := opts.set(fields[0], fields[1]); err != nil {
+			return opts, "", err
+		}
+		args = strings.TrimSpace(fields[2])
+	}
+	return opts, args, opts.check()
+}
+
+// Set the option by the name like `--binary`, also by the flags of the export subcommand
+func (opts *exportOptions) set(name string, value string) error {
+	switch name {
+	case "--binary":
+		if !contains(binaryModes, value) {
+			return fmt.Errorf("Unknown binary mode `%s', expect %s", value, strings.Join(binaryModes, ", "))
+		}
+		opts.binary = value
+	case "--compress":
+		if !contains(compressModes, value) {
+			return fmt.Errorf("Unknown compression `%s', expect %s", value, strings.Join(compressModes, ", "))
+		}
+		opts.compress = value
+	case "--split-rows":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("Expect a positive number of rows, got `%s'", value)
+		}
+		opts.splitRows = n
+	case "--split-size":
+		n, err := parseByteSize(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("Expect a positive size like 1GB, got `%s'", value)
+		}
+		opts.splitSize = n
+	case "--sample":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil || f <= 0 || f > 1 {
+			return fmt.Errorf("Expect the fraction in (0, 1], got `%s'", value)
+		}
+		opts.sample = f
+	case "--sample-rows":
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("Expect a positive number of rows, got `%s'", value)
+		}
+		opts.sampleRows = n
+	case "--anonymize":
+		columns, err := parseAnonymize(value)
+		if err != nil {
+			return err
+		}
+		opts.anonymize = columns
+	case "--anonymize-key":
+		opts.anonymizeKey = value
+	case "--canonical":
+		opts.canonical = parseCanonicalKey(value)
+	case "--seed":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("Expect an integer seed, got `%s'", value)
+		}
+		opts.sampleSeed = n
+	default:
+		return fmt.Errorf("Unknown option `%s'", name)
+	}
+	return nil
+}
+
+func (opts *exportOptions) check() error {
+	if opts.sample > 0 && opts.sampleRows > 0 {
+		return fmt.Errorf("Either --sample or --sample-rows")
+	}
+	return nil
+}
+
+const exportUsage = "Usage: :export [--binary escape|base64|replace] [--split-rows <n>] [--split-size <size>] " +
+	"[--compress none|gzip|zstd] [--sample <fraction>|--sample-rows <n> [--seed <n>]] " +
+	"[--anonymize <column[:hash|redact]>,... [--anonymize-key <key>]] [--canonical <key column,...|*>] <sink|file> <statement|@bookmark>"
+
+// :export [options] <sink name|file> <statement>
+func exportCmd(client *Session, c Cli, args string) error {
+	opts, args, err := parseExportOptions(args)
+	if err != nil {
+		return err
+	}
+	fields := strings.SplitN(args, " ", 2)
+	if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+		return fmt.Errorf(exportUsage)
+	}
+	return exportTo(client, c, opts, fields[0], fields[1])
+}
+
+// Export the result of the statement or the bookmark to the sink name or the file path,
+// not split by the spaces, e.g. the path from the arguments of the export subcommand
+func exportTo(client *Session, c Cli, opts exportOptions, target string, stmt string) error {
+	sink, ok := conf.Sinks[target]
+	if !ok {
+		// Not a named sink, treat as the file path
+		sink = Sink{Type: "file", Path: target}
+	}
+	// The option overrides the sink, then the `binary_strings' setting
+	if opts.binary == "" {
+		opts.binary = sink.Binary
+	}
+	if opts.binary == "" {
+		opts.binary = binaryStrings
+	}
+	if opts.compress != "" {
+		sink.Compress = opts.compress
+	}
+
+	// The bookmarked result by `@name', e.g. `:export out.csv @q_supernodes`
+	var resp *graph.ExecutionResponse
+	var err error
+	if name := strings.TrimSpace(stmt); strings.HasPrefix(name, "@") {
+		b, err := lookupBookmark(name[1:])
+		if err != nil {
+			return err
+		}
+		resp = b.resp
+	} else if err = confirmStatement(c, stmt); err != nil {
+		return err
+	} else if resp, err = client.Execute(stmt); err != nil {
+		return codedError{exitExecuteError, err}
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return fmt.Errorf("Execute failed, %s", errorString(resp))
+	}
+	if hashesColumns(opts.anonymize) && opts.anonymizeKey == "" {
+		if opts.anonymizeKey, err = randomAnonymizeKey(); err != nil {
+			return err
+		}
+		// Not teed with the results
+		fmt.Fprintf(os.Stderr, "Hashed by the random key %s, join with the later exports by `--anonymize-key' of it.", opts.anonymizeKey)
+		fmt.Fprintln(os.Stderr)
+	}
+	tables, err := anonymizeTables(sampleTables(resp.GetData(), opts), opts.anonymize, []byte(opts.anonymizeKey))
+	if err != nil {
+		return err
+	}
+	if tables, err = canonicalTables(tables, opts.canonical); err != nil {
+		return err
+	}
+	if opts.splitRows > 0 || opts.splitSize > 0 {
+		return exportParts(sink, tables, opts)
+	}
+
+	w, err := openSink(sink)
+	if err != nil {
+		return codedError{exitFileError, err}
+	}
+	if err = writeTables(w, tables, sink.Format, opts); err != nil {
+		w.Close()
+		return codedError{exitFileError, err}
+	}
+	rows := 0
+	for _, table := range tables {
+		rows += len(table.GetRows())
+	}
+	if err = w.Close(); err != nil {
+		return codedError{exitFileError, err}
+	}
+	fmt.Fprintf(out, "Exported %d rows to `%s'.", rows, target)
+	fmt.Fprintln(out)
+	return nil
+}
+
+// The options of `:export` as the flags of the subcommand, passed through in the same form
+var exportFlags = []struct{ name, usage string }{
+	{"binary", "The non-UTF8 strings, escape, base64 or replace, default by the sink"},
+	{"split-rows", "Split the file into the numbered parts of the rows"},
+	{"split-size", "Split the file into the numbered parts of the size like 1GB"},
+	{"compress", "Compress the file, none, gzip or zstd"},
+	{"sample", "Export the random rows by the fraction in (0, 1]"},
+	{"sample-rows", "Export the random rows by the count"},
+	{"seed", "The seed of the sampling"},
+	{"anonymize", "Anonymize the columns like email:hash,phone:redact"},
+	{"anonymize-key", "The secret key of the hashed columns, random by export if empty"},
+	{"canonical", "Export canonically for diffing, sorted by the key columns like vid, or * for all"},
+}
+
+// nebula-console export [flags] <sink|file> <statement>
+func exportMain(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	setUsage(fs, subcommands["export"].usage)
+	connFlags := addConnectionFlags(fs)
+	space := fs.String("space", "", "The space to USE before the statement")
+	configFile := fs.String("config", "", "The console configuration file with the sinks, default ~/.nebula_console.json")
+	for _, f := range exportFlags {
+		fs.String(f.name, "", f.usage)
+	}
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		fs.Usage()
+		return exitUsageError
+	}
+	opts := exportOptions{sampleSeed: defaultSampleSeed}
+	var optErr error
+	fs.Visit(func(fl *flag.Flag) {
+		for _, f := range exportFlags {
+			if f.name == fl.Name && optErr == nil {
+				optErr = opts.set("--"+fl.Name, fl.Value.String())
+			}
+		}
+	})
+	if optErr == nil {
+		optErr = opts.check()
+	}
+	if optErr != nil {
+		fmt.Fprintln(os.Stderr, optErr.Error())
+		return exitUsageError
+	}
+
+	home, err := consoleHome()
+	if err != nil {
+		log.Fatalf("%s", err.Error())
+	}
+	if *configFile == "" {
+		*configFile = filepath.Join(home, ".nebula_console.json")
+	}
+	if conf, err = loadConfig(*configFile); err != nil {
+		exitWith(exitFileError, "Load configuration %s failed, %s", *configFile, err.Error())
+	}
+	conn, err = connFlags.Connection()
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "%s", err.Error())
+	}
+	client, err := newSession(conn)
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "Fail to connect server, address: %s, username: %s, %s", conn.Address, conn.Username, err.Error())
+	}
+	defer client.Disconnect()
+	if *space != "" {
+		if resp, err := client.Execute("USE " + quoteName(*space)); err != nil {
+			log.Printf("Use space %s failed, %s", *space, err.Error())
+			return exitExecuteError
+		} else if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			log.Printf("Use space %s failed, %s", *space, errorString(resp))
+			return exitStatementError
+		}
+	}
+	// The path with the spaces is kept as the one argument, and the rest arguments are the statement
+	if err = exportTo(client, nil, opts, fs.Arg(0), strings.Join(fs.Args()[1:], " ")); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return errorExitCode(err, exitStatementError)
+	}
+	return 0
+}
@@ -0,0 +1,148 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Sessions executing concurrently
+const foreachConcurrency = 4
+
+// :foreach space IN (SHOW SPACES|s1, s2) [MATCH <glob>] DO <statement>
+var foreachPattern = regexp.MustCompile(`(?is)^space\s+IN\s+\((.*?)\)\s+(?:MATCH\s+(\S+)\s+)?DO\s+(.+)$`)
+
+// The spaces listed or returned by `SHOW SPACES`
+func foreachSpaces(client *Session, list string) ([]string, error) {
+	spaces := []string{}
+	if strings.ToUpper(strings.Join(strings.Fields(list), " ")) == "SHOW SPACES" {
+		resp, err := client.Execute("SHOW SPACES")
+		if err != nil {
+			return nil, err
+		}
+		if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			return nil, fmt.Errorf("SHOW SPACES failed, %s", errorString(resp))
+		}
+		for _, table := range resp.GetData() {
+			for _, row := range table.GetRows() {
+				if len(row.GetColumns()) > 0 {
+					spaces = append(spaces, exportValue(row.GetColumns()[0]))
+				}
+			}
+		}
+		return spaces, nil
+	}
+	for _, s := range strings.Split(list, ",") {
+		s = strings.Trim(strings.TrimSpace(s), "\"'`")
+		if s != "" {
+			spaces = append(spaces, s)
+		}
+	}
+	return spaces, nil
+}
+
+type foreachResult struct {
+	resp *graph.ExecutionResponse
+	err  error
+}
+
+// Execute the statement in the space by a dedicated session
+func foreachExecute(space string, stmt string) foreachResult {
+	session, err := newSession(conn)
+	if err != nil {
+		return foreachResult{nil, err}
+	}
+	defer session.Disconnect()
+	resp, err := session.Execute("USE " + quoteName(space))
+	if err != nil {
+		return foreachResult{nil, err}
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return foreachResult{nil, fmt.Errorf("USE failed, %s", errorString(resp))}
+	}
+	resp, err = session.Execute(stmt)
+	return foreachResult{resp, err}
+}
+
+func foreachCmd(client *Session, c Cli, args string) error {
+	m := foreachPattern.FindStringSubmatch(strings.TrimSpace(args))
+	if m == nil {
+		return fmt.Errorf("Usage: :foreach space IN (SHOW SPACES|s1, s2) [MATCH <glob>] DO <statement>")
+	}
+	if err := confirmStatement(c, m[3]); err != nil {
+		return err
+	}
+	spaces, err := foreachSpaces(client, m[1])
+	if err != nil {
+		return err
+	}
+	if m[2] != "" {
+		matched := []string{}
+		for _, s := range spaces {
+			if ok, err := path.Match(m[2], s); err != nil {
+				return err
+			} else if ok {
+				matched = append(matched, s)
+			}
+		}
+		spaces = matched
+	}
+
+	results := make([]foreachResult, len(spaces))
+	sem := make(chan struct{}, foreachConcurrency)
+	var wg sync.WaitGroup
+	for i, space := range spaces {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, space string) {
+			defer wg.Done()
+			results[i] = foreachExecute(space, m[3])
+			<-sem
+		}(i, space)
+	}
+	wg.Wait()
+
+	// Concatenate the tables with same columns, prefixed by the `space` column
+	merged := []*graph.DataSet{}
+	headers := map[string]*graph.DataSet{}
+	for i, result := range results {
+		if result.err != nil {
+			fmt.Fprintf(out, "[ERROR] space %s: %s", spaces[i], result.err.Error())
+			fmt.Fprintln(out)
+			continue
+		}
+		if result.resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			fmt.Fprintf(out, "[ERROR (%d)] space %s: %s", result.resp.GetErrorCode(), spaces[i], errorString(result.resp))
+			fmt.Fprintln(out)
+			continue
+		}
+		for _, table := range result.resp.GetData() {
+			key := strings.Join(columnNames(table), "\x00")
+			dataset, ok := headers[key]
+			if !ok {
+				dataset = &graph.DataSet{ColumnNames: append([][]byte{[]byte("space")}, table.GetColumnNames()...)}
+				headers[key] = dataset
+				merged = append(merged, dataset)
+			}
+			for _, row := range table.GetRows() {
+				columns := append([]*common.Value{{SVal: []byte(spaces[i])}}, row.GetColumns()...)
+				dataset.Rows = append(dataset.Rows, &graph.Row{Columns: columns})
+			}
+		}
+	}
+	for _, dataset := range merged {
+		t.PrintTable(dataset)
+	}
+	return nil
+}
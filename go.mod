@@ -1,6 +1,7 @@
 module vesoft-inc/shylock-hg/nebula-console2.0
 
 require (
+	github.com/facebook/fbthrift v0.0.0-20190922225929-2f9839604e25
 	github.com/shylock-hg/nebula-go2.0 v0.0.0-20200413085612-624240eb1372
 	github.com/shylock-hg/readline v0.0.0-20200417063605-a7cb88257b72
 )
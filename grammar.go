@@ -0,0 +1,212 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The keywords highlighted, the completion tree and the syntax hints, the built-in one is overridden
+// by the file, so the newer syntax is supported by updating the file without upgrading the console
+type grammar struct {
+	Keywords []string `json:"keywords"`
+	// Each entry is the words completed in sequence, e.g. `SHOW HOSTS`
+	Completions []string `json:"completions"`
+	// The syntax by the leading words of the statement
+	Hints map[string]string `json:"hints"`
+}
+
+const builtinGrammar = `{
+  "keywords": [
+    "GO", "FROM", "OVER", "WHERE", "YIELD", "STEPS", "UPTO", "REVERSELY", "BIDIRECT", "AS", "DISTINCT",
+    "FETCH", "PROP", "ON", "LOOKUP", "MATCH", "RETURN", "WITH", "UNWIND", "OPTIONAL", "ORDER", "BY", "ASC", "DESC", "LIMIT", "SKIP", "GROUP",
+    "FIND", "SHORTEST", "ALL", "PATH", "GET", "SUBGRAPH", "BOTH", "IN", "OUT", "TO", "USE", "SHOW", "DESCRIBE", "CREATE", "DROP", "ALTER",
+    "REBUILD", "INSERT", "UPDATE", "UPSERT", "DELETE", "VERTEX", "VERTICES", "EDGE", "EDGES", "TAG", "TAGS", "INDEX", "INDEXES", "SPACE", "SPACES",
+    "VALUES", "SET", "WHEN", "IF", "NOT", "EXISTS", "AND", "OR", "XOR", "NULL", "TRUE", "FALSE", "CONTAINS", "STARTS", "ENDS", "UNION", "INTERSECT", "MINUS",
+    "GRANT", "REVOKE", "ROLE", "ROLES", "USER", "USERS", "CHANGE", "PASSWORD", "HOSTS", "PARTS", "CONFIGS", "BALANCE", "LEADER", "DATA",
+    "CASE", "THEN", "ELSE", "END", "IS", "COUNT", "SUM", "AVG", "MAX", "MIN"
+  ],
+  "completions": [
+    "SHOW HOSTS", "SHOW SPACES", "SHOW PARTS", "SHOW TAGS", "SHOW EDGES", "SHOW USERS", "SHOW ROLES", "SHOW USER", "SHOW CONFIGS",
+    "DESCRIBE TAG", "DESCRIBE EDGE", "DESCRIBE SPACE", "DESC TAG", "DESC EDGE", "DESC SPACE",
+    "GET CONFIGS",
+    "CREATE SPACE", "CREATE TAG", "CREATE EDGE", "CREATE USER",
+    "DROP SPACE", "DROP TAG", "DROP EDGE", "DROP USER",
+    "ALTER USER", "ALTER TAG", "ALTER EDGE",
+    "INSERT VERTEX", "INSERT EDGE",
+    "UPDATE CONFIGS", "UPDATE VERTEX", "UPDATE EDGE",
+    "UPSERT VERTEX", "UPSERT EDGE",
+    "DELETE VERTEX", "DELETE EDGE",
+    "GRANT ROLE", "REVOKE ROLE", "CHANGE PASSWORD"
+  ],
+  "hints": {
+    "GO": "GO [<N> STEPS] FROM <vid_list> OVER <edge_type_list> [REVERSELY] [WHERE <expression>] [YIELD [DISTINCT] <return_list>]",
+    "FETCH PROP ON": "FETCH PROP ON <tag_name|edge_type> <vid_list|src->dst[@rank]> [YIELD [DISTINCT] <return_list>]",
+    "LOOKUP ON": "LOOKUP ON <tag_name|edge_type> WHERE <expression> [YIELD <return_list>]",
+    "MATCH": "MATCH <pattern> [WHERE <expression>] RETURN <return_list>",
+    "FIND PATH": "FIND SHORTEST|ALL PATH FROM <vid_list> TO <vid_list> OVER <edge_type_list> [UPTO <N> STEPS]",
+    "GET SUBGRAPH": "GET SUBGRAPH [<N> STEPS] FROM <vid_list> [IN|OUT|BOTH <edge_type_list>]",
+    "INSERT VERTEX": "INSERT VERTEX <tag_name> (<prop_name_list>) VALUES <vid>: (<prop_value_list>)[, ...]",
+    "INSERT EDGE": "INSERT EDGE <edge_type> (<prop_name_list>) VALUES <src_vid> -> <dst_vid>[@<rank>]: (<prop_value_list>)[, ...]",
+    "UPDATE VERTEX": "UPDATE VERTEX <vid> SET <update_list> [WHEN <condition>] [YIELD <return_list>]",
+    "UPDATE EDGE": "UPDATE EDGE <src_vid> -> <dst_vid>[@<rank>] OF <edge_type> SET <update_list> [WHEN <condition>] [YIELD <return_list>]",
+    "DELETE VERTEX": "DELETE VERTEX <vid_list>",
+    "DELETE EDGE": "DELETE EDGE <edge_type> <src_vid> -> <dst_vid>[@<rank>][, ...]",
+    "CREATE SPACE": "CREATE SPACE [IF NOT EXISTS] <space_name> [(partition_num = <N>, replica_factor = <N>, vid_size = <N>)]",
+    "CREATE TAG": "CREATE TAG [IF NOT EXISTS] <tag_name> (<prop_name> <data_type>[, ...]) [ttl_duration = <N>, ttl_col = <prop_name>]",
+    "CREATE EDGE": "CREATE EDGE [IF NOT EXISTS] <edge_type> (<prop_name> <data_type>[, ...]) [ttl_duration = <N>, ttl_col = <prop_name>]",
+    "ALTER TAG": "ALTER TAG <tag_name> ADD|CHANGE|DROP (<prop_name> [<data_type>][, ...])",
+    "ALTER EDGE": "ALTER EDGE <edge_type> ADD|CHANGE|DROP (<prop_name> [<data_type>][, ...])",
+    "GRANT ROLE": "GRANT ROLE <ADMIN|DBA|USER|GUEST> ON <space_name> TO <user>",
+    "REVOKE ROLE": "REVOKE ROLE <ADMIN|DBA|USER|GUEST> ON <space_name> FROM <user>",
+    "CHANGE PASSWORD": "CHANGE PASSWORD <user> FROM \"<old>\" TO \"<new>\""
+  }
+}`
+
+// The override file, reloaded when modified
+var grammarFile = ""
+
+var (
+	grammarSource  = "built-in"
+	grammarModTime time.Time
+	grammarApplied = false
+	syntaxHints    = map[string]string{}
+)
+
+// Apply the built-in grammar at the first use instead of the startup, which the -e/-f runs rarely need
+func useGrammar() {
+	if grammarApplied {
+		return
+	}
+	g, err := parseGrammar([]byte(builtinGrammar))
+	if err != nil {
+		panic(err)
+	}
+	applyGrammar(g)
+}
+
+func parseGrammar(content []byte) (*grammar, error) {
+	g := &grammar{}
+	if err := json.Unmarshal(content, g); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// Replace the keywords, the completions and the hints, then the extra completions of the configuration
+func applyGrammar(g *grammar) {
+	keywords := make(map[string]bool, len(g.Keywords))
+	for _, keyword := range g.Keywords {
+		keywords[strings.ToUpper(keyword)] = true
+	}
+	nGQLKeywords = keywords
+	completer.SetChildren(nil)
+	if completionEnabled {
+		for _, entry := range g.Completions {
+			addCompletion(completer, entry)
+		}
+		loadCompletions(conf)
+	}
+	hints := make(map[string]string, len(g.Hints))
+	for words, hint := range g.Hints {
+		hints[strings.ToUpper(strings.Join(strings.Fields(words), " "))] = hint
+	}
+	syntaxHints = hints
+	grammarApplied = true
+}
+
+// Apply the file if exists, otherwise the built-in one, the invalid file is warned and the current grammar kept
+func reloadGrammar() error {
+	content := []byte(builtinGrammar)
+	source := "built-in"
+	modTime := time.Time{}
+	if grammarFile != "" {
+		info, err := os.Stat(grammarFile)
+		if err == nil {
+			if content, err = ioutil.ReadFile(grammarFile); err != nil {
+				return err
+			}
+			source, modTime = grammarFile, info.ModTime()
+		} else if !os.IsNotExist(err) {
+			return err
+		}
+	}
+	g, err := parseGrammar(content)
+	if err != nil {
+		grammarModTime = modTime
+		return fmt.Errorf("Invalid grammar %s, %s", source, err.Error())
+	}
+	applyGrammar(g)
+	grammarSource, grammarModTime = source, modTime
+	return nil
+}
+
+// Called before the prompt, reload if the file was created, modified or removed
+func reloadGrammarIfChanged() {
+	if grammarFile == "" {
+		return
+	}
+	modTime := time.Time{}
+	if info, err := os.Stat(grammarFile); err == nil {
+		modTime = info.ModTime()
+	}
+	if modTime.Equal(grammarModTime) {
+		return
+	}
+	if err := reloadGrammar(); err != nil {
+		fmt.Printf("[WARNING] %s", err.Error())
+		fmt.Println()
+		return
+	}
+	fmt.Printf("[NOTICE] Reloaded the grammar from %s.", grammarSource)
+	fmt.Println()
+}
+
+// :grammar [reload], show the grammar loaded without arguments
+func grammarCmd(client *Session, c Cli, args string) error {
+	useGrammar()
+	switch strings.TrimSpace(args) {
+	case "":
+		fmt.Printf("Grammar from %s, %d keywords and %d hints, override by %s.", grammarSource, len(nGQLKeywords), len(syntaxHints), grammarFile)
+		fmt.Println()
+		return nil
+	case "reload":
+		if err := reloadGrammar(); err != nil {
+			return err
+		}
+		fmt.Printf("Reloaded the grammar from %s.", grammarSource)
+		fmt.Println()
+		return nil
+	}
+	return fmt.Errorf("Usage: :grammar [reload]")
+}
+
+// :syntax [words], e.g. `:syntax INSERT`, list the hints of the statements beginning with the words
+func syntaxCmd(client *Session, c Cli, args string) error {
+	useGrammar()
+	prefix := strings.ToUpper(strings.Join(strings.Fields(args), " "))
+	keys := []string{}
+	for words := range syntaxHints {
+		if strings.HasPrefix(words, prefix) {
+			keys = append(keys, words)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("No syntax of `%s'", strings.TrimSpace(args))
+	}
+	sort.Strings(keys)
+	for _, words := range keys {
+		fmt.Println(syntaxHints[words])
+	}
+	return nil
+}
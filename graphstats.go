@@ -0,0 +1,113 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The weakly connected components by union-find, returns the sizes from the largest
+func componentSizes(g *dotGraph) []int {
+	parent := make(map[string]string, len(g.nodes))
+	var find func(string) string
+	find = func(v string) string {
+		if parent[v] != v {
+			parent[v] = find(parent[v])
+		}
+		return parent[v]
+	}
+	for _, node := range g.nodes {
+		parent[node] = node
+	}
+	for _, link := range g.links {
+		if a, b := find(link[0]), find(link[1]); a != b {
+			parent[a] = b
+		}
+	}
+	counts := map[string]int{}
+	for _, node := range g.nodes {
+		counts[find(node)]++
+	}
+	sizes := make([]int, 0, len(counts))
+	for _, n := range counts {
+		sizes = append(sizes, n)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sizes)))
+	return sizes
+}
+
+func int64Value(v int64) *common.Value {
+	return &common.Value{IVal: &v}
+}
+
+func float64Value(v float64) *common.Value {
+	return &common.Value{FVal: &v}
+}
+
+// :graph-stats
+// The metrics of the subgraph formed by the vertices, edges and paths in the last result
+func graphStatsCmd(client *Session, c Cli, args string) error {
+	if lastResp == nil {
+		return fmt.Errorf("No result")
+	}
+	g := newDotGraph()
+	for _, table := range lastResp.GetData() {
+		g.addTable(table)
+	}
+	if len(g.nodes) == 0 {
+		return fmt.Errorf("No vertices, edges or paths in the last result")
+	}
+
+	// The degree counts both directions, the self loop counts twice
+	degree := make(map[string]int, len(g.nodes))
+	for _, link := range g.links {
+		degree[link[0]]++
+		degree[link[1]]++
+	}
+	distribution := map[int]int64{}
+	minDegree, maxDegree := -1, 0
+	for _, node := range g.nodes {
+		d := degree[node]
+		distribution[d]++
+		if minDegree < 0 || d < minDegree {
+			minDegree = d
+		}
+		if d > maxDegree {
+			maxDegree = d
+		}
+	}
+	sizes := componentSizes(g)
+
+	summary := &graph.DataSet{ColumnNames: [][]byte{[]byte("metric"), []byte("value")}}
+	metric := func(name string, value *common.Value) {
+		summary.Rows = append(summary.Rows, &graph.Row{Columns: []*common.Value{{SVal: []byte(name)}, value}})
+	}
+	metric("nodes", int64Value(int64(len(g.nodes))))
+	metric("edges", int64Value(int64(len(g.links))))
+	metric("min degree", int64Value(int64(minDegree)))
+	metric("max degree", int64Value(int64(maxDegree)))
+	metric("avg degree", float64Value(float64(2*len(g.links))/float64(len(g.nodes))))
+	metric("components", int64Value(int64(len(sizes))))
+	metric("largest component", int64Value(int64(sizes[0])))
+	t.PrintTable(summary)
+
+	degrees := make([]int, 0, len(distribution))
+	for d := range distribution {
+		degrees = append(degrees, d)
+	}
+	sort.Ints(degrees)
+	table := &graph.DataSet{ColumnNames: [][]byte{[]byte("degree"), []byte("nodes")}}
+	for _, d := range degrees {
+		table.Rows = append(table.Rows, &graph.Row{Columns: []*common.Value{int64Value(int64(d)), int64Value(distribution[d])}})
+	}
+	t.PrintTable(table)
+	return nil
+}
@@ -0,0 +1,188 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Highlight the input line as typing, only if the colors enabled
+var highlightInput = true
+
+const (
+	colorBlue    = "\033[34m"
+	colorInverse = "\033[7m"
+)
+
+// The keywords by the grammar
+var nGQLKeywords = map[string]bool{}
+
+func isBracket(r rune) bool {
+	return strings.ContainsRune("()[]{}", r)
+}
+
+// The index of the matched bracket, or -1, the brackets in strings are skipped
+func matchBracket(line []rune, quoted []bool, i int) int {
+	pairs := map[rune]rune{'(': ')', '[': ']', '{': '}', ')': '(', ']': '[', '}': '{'}
+	left, right := line[i], pairs[line[i]]
+	step := 1
+	if strings.ContainsRune(")]}", left) {
+		step = -1
+	}
+	depth := 0
+	for j := i; j >= 0 && j < len(line); j += step {
+		if quoted[j] {
+			continue
+		}
+		switch line[j] {
+		case left:
+			depth++
+		case right:
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return -1
+}
+
+// The color of each rune: keywords, strings, numbers, and the bracket matched around the cursor
+func highlightColors(line []rune, pos int) []string {
+	colors := make([]string, len(line))
+	quoted := make([]bool, len(line))
+	for i := 0; i < len(line); {
+		r := line[i]
+		switch {
+		case r == '"' || r == '\'' || r == '`':
+			j := i + 1
+			for j < len(line) && line[j] != r {
+				if line[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(line) {
+				j = len(line) - 1
+			}
+			color := colorGreen
+			if r == '`' {
+				// The quoted names
+				color = ""
+			}
+			for k := i; k <= j; k++ {
+				colors[k], quoted[k] = color, true
+			}
+			i = j + 1
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			j := i
+			for j < len(line) && (unicode.IsLetter(line[j]) || unicode.IsDigit(line[j]) || line[j] == '_' || line[j] == '$') {
+				j++
+			}
+			// The property like `player.count` is not a keyword
+			if nGQLKeywords[strings.ToUpper(string(line[i:j]))] && (i == 0 || line[i-1] != '.') {
+				for k := i; k < j; k++ {
+					colors[k] = colorBlue
+				}
+			}
+			i = j
+		case unicode.IsDigit(r):
+			j := i
+			for j < len(line) && (unicode.IsDigit(line[j]) || line[j] == '.') {
+				j++
+			}
+			for k := i; k < j; k++ {
+				colors[k] = colorCyan
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	// The bracket at or before the cursor
+	for _, i := range []int{pos, pos - 1} {
+		if i >= 0 && i < len(line) && isBracket(line[i]) && !quoted[i] {
+			if j := matchBracket(line, quoted, i); j >= 0 {
+				colors[i], colors[j] = colorInverse, colorInverse
+			} else {
+				colors[i] = colorRed
+			}
+			break
+		}
+	}
+	return colors
+}
+
+// The readline painter coloring the input line on TTY
+type nGQLPainter struct {
+	isTTY bool
+}
+
+func (p nGQLPainter) Paint(line []rune, pos int) []rune {
+	if !p.isTTY || !colorEnabled() || len(line) == 0 {
+		return line
+	}
+	painted := line
+	if highlightInput {
+		painted = paintColors(line, pos)
+	}
+	// The dimmed suggestion after the cursor at the end, then move the cursor back
+	if suggestion := inputSuggestion(line, pos); suggestion != "" {
+		painted = append(append([]rune{}, painted...), []rune(colorGray+suggestion+colorReset)...)
+		painted = append(painted, []rune(fmt.Sprintf("\033[%dD", stringWidth(suggestion)))...)
+	}
+	return painted
+}
+
+func paintColors(line []rune, pos int) []rune {
+	colors := highlightColors(line, pos)
+	painted := make([]rune, 0, len(line)*2)
+	current := ""
+	for i, r := range line {
+		if colors[i] != current {
+			if current != "" {
+				painted = append(painted, []rune(colorReset)...)
+			}
+			painted = append(painted, []rune(colors[i])...)
+			current = colors[i]
+		}
+		painted = append(painted, r)
+	}
+	if current != "" {
+		painted = append(painted, []rune(colorReset)...)
+	}
+	return painted
+}
+
+// Suggest completing the input from the history, accepted by the right arrow
+var autoSuggest = true
+
+// The right arrow, or Ctrl+F
+const keyForward = 6
+
+func inputSuggestion(line []rune, pos int) string {
+	if !autoSuggest || pos != len(line) {
+		return ""
+	}
+	return stmtHistory.suggest(string(line))
+}
+
+type suggestionListener struct{}
+
+func (suggestionListener) OnChange(line []rune, pos int, key rune) ([]rune, int, bool) {
+	if key != keyForward {
+		return nil, 0, false
+	}
+	suggestion := inputSuggestion(line, pos)
+	if suggestion == "" {
+		return nil, 0, false
+	}
+	newLine := append(append([]rune{}, line...), []rune(suggestion)...)
+	return newLine, len(newLine), true
+}
@@ -0,0 +1,122 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Keep the last entries in the statements history file
+const maxHistoryEntries = 1000
+
+// The complete statements and commands entered interactively, unlike the per-line readline history
+type history struct {
+	file    string
+	entries []string
+}
+
+var stmtHistory = &history{}
+
+// Neither load nor save the history files by `--no-history`, the entries are kept in the session only
+var historyEnabled = true
+
+// Each entry is one quoted line to keep the multi-line statements
+func loadHistory(file string) {
+	stmtHistory = &history{file, nil}
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if entry, err := strconv.Unquote(scanner.Text()); err == nil {
+			stmtHistory.entries = append(stmtHistory.entries, entry)
+		}
+	}
+	if len(stmtHistory.entries) > maxHistoryEntries {
+		stmtHistory.entries = stmtHistory.entries[len(stmtHistory.entries)-maxHistoryEntries:]
+		var sb strings.Builder
+		for _, entry := range stmtHistory.entries {
+			sb.WriteString(strconv.Quote(entry) + "\n")
+		}
+		ioutil.WriteFile(file, []byte(sb.String()), 0600)
+	}
+}
+
+func (h *history) record(entry string) {
+	entry = strings.TrimSpace(entry)
+	if entry == "" || isSecretStatement(entry) || (len(h.entries) > 0 && h.entries[len(h.entries)-1] == entry) {
+		return
+	}
+	h.entries = append(h.entries, entry)
+	if h.file == "" {
+		return
+	}
+	fd, err := os.OpenFile(h.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer fd.Close()
+	fmt.Fprintln(fd, strconv.Quote(entry))
+}
+
+// The rest of the most recent single-line entry beginning with the prefix, empty if none
+func (h *history) suggest(prefix string) string {
+	if strings.TrimSpace(prefix) == "" {
+		return ""
+	}
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := h.entries[i]
+		if len(entry) > len(prefix) && strings.HasPrefix(entry, prefix) && !strings.Contains(entry, "\n") {
+			return entry[len(prefix):]
+		}
+	}
+	return ""
+}
+
+// `!!` recalls the last entry and `!N` the Nth one, the others like `!ls` are the shell commands
+var recallPattern = regexp.MustCompile(`^!(!|\d+)$`)
+
+// Returns the recalled entry and whether the line is a recall
+func (h *history) recall(line string) (string, bool, error) {
+	m := recallPattern.FindStringSubmatch(strings.TrimSpace(line))
+	if m == nil {
+		return "", false, nil
+	}
+	if m[1] == "!" {
+		if len(h.entries) == 0 {
+			return "", true, fmt.Errorf("No history")
+		}
+		return h.entries[len(h.entries)-1], true, nil
+	}
+	n, _ := strconv.Atoi(m[1])
+	if n < 1 || n > len(h.entries) {
+		return "", true, fmt.Errorf("No history entry %d", n)
+	}
+	return h.entries[n-1], true, nil
+}
+
+// :history [pattern]
+// List the numbered entries containing the pattern case-insensitively, recalled by `!N`
+func historyCmd(client *Session, c Cli, args string) error {
+	pattern := strings.ToLower(strings.TrimSpace(args))
+	for i, entry := range stmtHistory.entries {
+		if pattern != "" && !strings.Contains(strings.ToLower(entry), pattern) {
+			continue
+		}
+		fmt.Printf("%5d  %s", i+1, strings.Replace(entry, "\n", "\n       ", -1))
+		fmt.Println()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"html"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Print the <table> only by `--html-fragment`, e.g. embedded in the reports
+var htmlFragment = false
+
+const htmlHead = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Nebula Graph Result</title>
+<style>
+table { border-collapse: collapse; font-family: monospace; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+th { background: #f0f0f0; }
+td.number { text-align: right; }
+</style>
+</head>
+<body>`
+
+const htmlTail = `</body>
+</html>`
+
+// Print the HTML tables, the values escaped and the numeric cells right aligned,
+// the results of the response in the same standalone document unless `--html-fragment`
+func (t Table) PrintHTML(tables ...*graph.DataSet) {
+	out.buffer()
+	defer out.unbuffer()
+	if !htmlFragment {
+		fmt.Fprintln(out, htmlHead)
+	}
+	for _, table := range tables {
+		printHTMLTable(table)
+	}
+	if !htmlFragment {
+		fmt.Fprintln(out, htmlTail)
+	}
+}
+
+func printHTMLTable(table *graph.DataSet) {
+	header := columnNames(table)
+	secret := secretColumns(header)
+	fmt.Fprintln(out, "<table>")
+	fmt.Fprint(out, "<thead><tr>")
+	for _, name := range header {
+		fmt.Fprintf(out, "<th>%s</th>", html.EscapeString(name))
+	}
+	fmt.Fprintln(out, "</tr></thead>")
+	fmt.Fprintln(out, "<tbody>")
+	for _, row := range table.GetRows() {
+		fmt.Fprint(out, "<tr>")
+		for j, col := range row.GetColumns() {
+			value := val2String(col, 256)
+			if secret != nil && secret[j] {
+				value = secretMask
+			}
+			if col.IsSetIVal() || col.IsSetFVal() {
+				fmt.Fprintf(out, `<td class="number">%s</td>`, html.EscapeString(value))
+			} else {
+				fmt.Fprintf(out, "<td>%s</td>", html.EscapeString(value))
+			}
+		}
+		fmt.Fprintln(out, "</tr>")
+	}
+	fmt.Fprintln(out, "</tbody>")
+	fmt.Fprintln(out, "</table>")
+}
@@ -0,0 +1,115 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Changed by `:set hyperlinks`, auto renders the links only if the terminal supports OSC 8
+var hyperlinkMode = "auto"
+
+// The space of the result being printed, referenced by the vertex links
+var resultSpace = ""
+
+// The scheme of the vertex links, pasting the link at the prompt fetches the vertex
+const linkScheme = "nebula-console://fetch"
+
+// The terminals known to support the OSC 8 hyperlinks
+func hyperlinksSupported() bool {
+	if !stdoutIsTTY {
+		return false
+	}
+	switch os.Getenv("TERM_PROGRAM") {
+	case "iTerm.app", "WezTerm", "vscode", "Hyper":
+		return true
+	}
+	if v, err := strconv.Atoi(os.Getenv("VTE_VERSION")); err == nil && v >= 5000 {
+		return true
+	}
+	return os.Getenv("WT_SESSION") != "" || os.Getenv("KONSOLE_VERSION") != "" ||
+		os.Getenv("TERM") == "xterm-kitty"
+}
+
+func hyperlinksEnabled() bool {
+	return hyperlinkMode == "always" || (hyperlinkMode == "auto" && hyperlinksSupported())
+}
+
+// The link to fetch the vertex, empty if not a vertex or disabled
+func vertexLink(value *common.Value) string {
+	if !value.IsSetVVal() || !hyperlinksEnabled() {
+		return ""
+	}
+	query := url.Values{}
+	query.Set("space", resultSpace)
+	query.Set("vid", string(value.GetVVal().GetVid()))
+	return linkScheme + "?" + query.Encode()
+}
+
+// Wrap the text by the OSC 8 escape sequences, the terminal shows the text only
+func hyperlink(text string, link string) string {
+	if link == "" {
+		return text
+	}
+	return "\033]8;;" + link + "\033\\" + text + "\033]8;;\033\\"
+}
+
+// The statement fetching the vertex of the link pasted at the prompt, the space of the link
+// if not the current one, and whether the line is a link
+func linkStatement(client *Session, line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, linkScheme) {
+		return "", "", false, nil
+	}
+	u, err := url.Parse(line)
+	if err != nil {
+		return "", "", true, err
+	}
+	space, vid := u.Query().Get("space"), u.Query().Get("vid")
+	if vid == "" {
+		return "", "", true, fmt.Errorf("No vid in the link `%s'", line)
+	}
+	if space == client.space {
+		space = ""
+	}
+	// The vid type of the link space decides the quoting
+	target := *client
+	if space != "" {
+		target.space = space
+	}
+	if !isIntVidSpace(&target) {
+		vid = quoteVid(vid)
+	}
+	return fmt.Sprintf("FETCH PROP ON * %s;", vid), space, true, nil
+}
+
+// Fetch the vertex of the link in another space by the dedicated session,
+// the current space of the console isn't switched by following the link
+func fetchLink(client *Session, c Cli, space string, stmt string) error {
+	session, err := newSession(client.conn)
+	if err != nil {
+		return err
+	}
+	defer session.Disconnect()
+	resp, err := session.Execute("USE " + quoteName(space))
+	if err != nil {
+		return fmt.Errorf("USE %s failed, %s", space, err.Error())
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return fmt.Errorf("USE %s failed, %s", space, errorString(resp))
+	}
+	_, err = runStatement(session, c, stmt)
+	c.SetSpace(client.space)
+	return err
+}
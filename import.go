@@ -0,0 +1,490 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Import the CSV file by the generated INSERT VERTEX/EDGE statements
+type importer struct {
+	client     *Session
+	space      string
+	tag        string
+	edge       string
+	vidColumn  string
+	srcColumn  string
+	dstColumn  string
+	rankColumn string
+	batchSize  int
+	rateLimit  int  // rows per second, 0 means no limit
+	comma      rune // the fields delimiter, 0 means `,'
+	// Map the fields to the id and properties in order if the first row is not the header
+	detectHeader bool
+	// Insert without validating the fields by the property types first
+	skipValidation bool
+	// Compute the columns from the source ones, nil to use the source columns directly
+	mapping *importMapping
+	// The live throughput, nil if disabled
+	progress *dashboard
+	// Generate the statements without executing by `--dry-run`, the first preview ones are printed
+	dryRun  bool
+	preview int
+	plan    importPlan
+
+	// Property name to its type, from DESCRIBE TAG/EDGE
+	schema map[string]string
+	// Property names in the schema order
+	props []string
+	// The properties declared NOT NULL
+	notNull map[string]bool
+	intVid  bool
+	// The empty field of the string properties, `null' or the `empty' string by --empty-as
+	emptyAs string
+}
+
+// The batches generated by the dry run
+type importPlan struct {
+	header   []string
+	props    []string
+	batches  int
+	rows     int
+	minBytes int
+	maxBytes int
+	bytes    int64
+}
+
+func (p *importPlan) add(rows int, stmt string) {
+	if p.batches == 0 || len(stmt) < p.minBytes {
+		p.minBytes = len(stmt)
+	}
+	if len(stmt) > p.maxBytes {
+		p.maxBytes = len(stmt)
+	}
+	p.batches++
+	p.rows += rows
+	p.bytes += int64(len(stmt))
+}
+
+// The target schema, the columns mapped and the batches, to verify before the long load
+func (im *importer) printPlan() {
+	p := im.plan
+	kind := "TAG"
+	if im.edge != "" {
+		kind = "EDGE"
+	}
+	typed := make([]string, len(im.plan.props))
+	for i, prop := range p.props {
+		typed[i] = prop + " " + im.schema[prop]
+	}
+	vidType := "string"
+	if im.intVid {
+		vidType = "integer"
+	}
+	fmt.Printf("Target: space %s, %s %s(%s), %s vids", im.space, kind, im.schemaName(), strings.Join(typed, ", "), vidType)
+	fmt.Println()
+	if p.header != nil {
+		fmt.Printf("Columns: %s", strings.Join(p.header, ", "))
+		fmt.Println()
+	}
+	fmt.Printf("Batches: %d of up to %d rows, %d rows in total", p.batches, im.batchSize, p.rows)
+	fmt.Println()
+	if p.batches > 0 {
+		fmt.Printf("Statement size: min %s, avg %s, max %s, %s in total", formatByteSize(int64(p.minBytes)),
+			formatByteSize(p.bytes/int64(p.batches)), formatByteSize(int64(p.maxBytes)), formatByteSize(p.bytes))
+		fmt.Println()
+	}
+	if im.rateLimit > 0 {
+		fmt.Printf("Estimated time: %s by the rate limit %d rows/s", (time.Duration(p.rows) * time.Second / time.Duration(im.rateLimit)).Round(time.Second), im.rateLimit)
+		fmt.Println()
+	}
+}
+
+func (im *importer) schemaName() string {
+	if im.tag != "" {
+		return im.tag
+	}
+	return im.edge
+}
+
+func (im *importer) describe() error {
+	stmt := "DESCRIBE TAG " + quoteName(im.tag)
+	if im.edge != "" {
+		stmt = "DESCRIBE EDGE " + quoteName(im.edge)
+	}
+	resp, err := im.client.Execute(stmt)
+	if err != nil {
+		return codedError{exitExecuteError, err}
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return fmt.Errorf("%s failed, %s", stmt, errorString(resp))
+	}
+	im.schema = map[string]string{}
+	im.notNull = map[string]bool{}
+	im.props = nil
+	for _, table := range resp.GetData() {
+		for _, row := range table.GetRows() {
+			// Field, Type, ...
+			if len(row.GetColumns()) >= 2 {
+				name := exportValue(row.GetColumns()[0])
+				im.schema[name] = strings.ToLower(exportValue(row.GetColumns()[1]))
+				im.props = append(im.props, name)
+				// Field, Type, Null, Default
+				if len(row.GetColumns()) >= 3 && strings.EqualFold(exportValue(row.GetColumns()[2]), "NO") {
+					im.notNull[name] = true
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Whether all the fields are the id column or properties
+func (im *importer) isHeader(record []string) bool {
+	for _, name := range record {
+		_, ok := im.schema[name]
+		if !ok && name != im.vidColumn && name != im.srcColumn && name != im.dstColumn && name != im.rankColumn {
+			return false
+		}
+	}
+	return true
+}
+
+// The header of the fields without header: vid, or src and dst, then the properties in the schema order
+func (im *importer) positionalHeader(n int) ([]string, error) {
+	header := []string{im.vidColumn}
+	if im.edge != "" {
+		header = []string{im.srcColumn, im.dstColumn}
+	}
+	if n > len(header)+len(im.props) {
+		return nil, fmt.Errorf("%d fields exceed the %d properties of %s", n-len(header), len(im.props), im.schemaName())
+	}
+	if n < len(header) {
+		return nil, fmt.Errorf("Expect at least %d fields, got %d", len(header), n)
+	}
+	return append(header, im.props[:n-len(header)]...), nil
+}
+
+// The empty field is NULL unless of the string property imported by `--empty-as empty',
+// since the CSV can't tell the missing value from the empty string
+func (im *importer) emptyIsNull(typ string) bool {
+	return im.emptyAs != emptyAsString || (typ != "string" && !fixedStringTypePattern.MatchString(typ))
+}
+
+const (
+	emptyAsNull   = "null"
+	emptyAsString = "empty"
+)
+
+// Format the CSV field as nGQL literal by the property type
+func (im *importer) formatProp(value string, typ string) string {
+	if value == "" && im.emptyIsNull(typ) {
+		return "NULL"
+	}
+	switch {
+	case typ == "date", typ == "datetime", typ == "time":
+		return fmt.Sprintf("%s(%s)", typ, quoteVid(value))
+	case strings.Contains(typ, "int"), strings.Contains(typ, "double"), strings.Contains(typ, "float"),
+		typ == "bool", typ == "timestamp":
+		return value
+	}
+	return quoteVid(value)
+}
+
+func (im *importer) formatVid(vid string) string {
+	if im.intVid {
+		return vid
+	}
+	return quoteVid(vid)
+}
+
+// The header column indexes of the ids and properties
+type importColumns struct {
+	id    int // vid or src
+	dst   int
+	rank  int
+	props []int
+}
+
+func (im *importer) mapColumns(header []string) (importColumns, error) {
+	cols := importColumns{-1, -1, -1, []int{}}
+	idColumn := im.vidColumn
+	if im.edge != "" {
+		idColumn = im.srcColumn
+	}
+	for i, name := range header {
+		switch {
+		case name == idColumn:
+			cols.id = i
+		case im.edge != "" && name == im.dstColumn:
+			cols.dst = i
+		case im.edge != "" && name == im.rankColumn:
+			cols.rank = i
+		default:
+			if _, ok := im.schema[name]; !ok {
+				return cols, fmt.Errorf("Column `%s' is not a property of %s", name, im.schemaName())
+			}
+			cols.props = append(cols.props, i)
+		}
+	}
+	if cols.id < 0 {
+		return cols, fmt.Errorf("Column `%s' not found", idColumn)
+	}
+	if im.edge != "" && cols.dst < 0 {
+		return cols, fmt.Errorf("Column `%s' not found", im.dstColumn)
+	}
+	return cols, nil
+}
+
+// INSERT VERTEX t(p1, p2) VALUES "v1":(...), ... or INSERT EDGE e(p1) VALUES "a"->"b"@0:(...), ...
+func (im *importer) statement(header []string, cols importColumns, records [][]string) string {
+	props := make([]string, len(cols.props))
+	for i, c := range cols.props {
+		props[i] = header[c]
+	}
+	values := make([]string, len(records))
+	for i, record := range records {
+		fields := make([]string, len(cols.props))
+		for j, c := range cols.props {
+			fields[j] = im.formatProp(record[c], im.schema[header[c]])
+		}
+		key := im.formatVid(record[cols.id])
+		if im.edge != "" {
+			key += "->" + im.formatVid(record[cols.dst])
+			if cols.rank >= 0 && record[cols.rank] != "" {
+				key += "@" + record[cols.rank]
+			}
+		}
+		values[i] = fmt.Sprintf("%s:(%s)", key, strings.Join(fields, ", "))
+	}
+	kind := "VERTEX"
+	if im.edge != "" {
+		kind = "EDGE"
+	}
+	return fmt.Sprintf("INSERT %s %s(%s) VALUES %s", kind, quoteName(im.schemaName()), strings.Join(props, ", "), strings.Join(values, ", "))
+}
+
+func (im *importer) newReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	if im.comma != 0 {
+		reader.Comma = im.comma
+		// The pasted cells may contain the bare quotes
+		reader.LazyQuotes = im.comma == '\t'
+	}
+	return reader
+}
+
+// The records mapped if the mapping is specified
+func (im *importer) records(reader *csv.Reader) recordReader {
+	if im.mapping == nil {
+		return reader
+	}
+	return &mappedReader{reader: reader, mapping: im.mapping, schema: im.schema}
+}
+
+// Read the header, returns the first row too if it's data instead
+func (im *importer) readHeader(reader recordReader) ([]string, importColumns, []string, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, importColumns{}, nil, err
+	}
+	var first []string
+	if im.detectHeader && !im.isHeader(header) {
+		first = header
+		if header, err = im.positionalHeader(len(header)); err != nil {
+			return nil, importColumns{}, nil, err
+		}
+	}
+	cols, err := im.mapColumns(header)
+	return header, cols, first, err
+}
+
+// Import all rows, returns the rows imported and failed
+func (im *importer) run(r io.Reader) (int, int, error) {
+	if !im.skipValidation {
+		rs, err := im.validate(r)
+		if err != nil {
+			return 0, 0, err
+		}
+		r = rs
+	}
+	reader := im.records(im.newReader(im.progress.track(r)))
+	header, cols, first, err := im.readHeader(reader)
+	if err != nil {
+		return 0, 0, err
+	}
+	batch := make([][]string, 0, im.batchSize)
+	if first != nil {
+		// The first row is data
+		batch = append(batch, first)
+	}
+
+	imported, failed := 0, 0
+	start := time.Now()
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		stmt := im.statement(header, cols, batch)
+		if im.dryRun {
+			if im.plan.batches == 0 {
+				im.plan.header = header
+				for _, c := range cols.props {
+					im.plan.props = append(im.plan.props, header[c])
+				}
+			}
+			im.plan.add(len(batch), stmt)
+			if im.plan.batches <= im.preview {
+				im.progress.clear()
+				fmt.Printf("-- Batch %d, %d rows", im.plan.batches, len(batch))
+				fmt.Println()
+				fmt.Printf("%s;", stmt)
+				fmt.Println()
+			}
+			batch = batch[:0]
+			return
+		}
+		im.progress.begin()
+		resp, err := im.client.Execute(stmt)
+		if err == nil && resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED {
+			imported += len(batch)
+			im.progress.end(len(batch), 0)
+		} else {
+			im.progress.end(0, len(batch))
+			im.progress.clear()
+			if err != nil {
+				fmt.Printf("[ERROR] Insert %d rows failed, %s", len(batch), err.Error())
+			} else {
+				fmt.Printf("[ERROR (%d)] Insert %d rows failed, %s", resp.GetErrorCode(), len(batch), errorString(resp))
+			}
+			fmt.Println()
+			failed += len(batch)
+		}
+		batch = batch[:0]
+		// Wait for the rate limit
+		if im.rateLimit > 0 {
+			expected := time.Duration(float64(imported+failed) / float64(im.rateLimit) * float64(time.Second))
+			if elapsed := time.Since(start); elapsed < expected {
+				time.Sleep(expected - elapsed)
+			}
+		}
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			flush()
+			return imported, failed, err
+		}
+		batch = append(batch, record)
+		if len(batch) >= im.batchSize {
+			flush()
+		}
+	}
+	flush()
+	return imported, failed, nil
+}
+
+// nebula-console import --space <space> --tag <tag>|--edge <edge> --file <csv> [options]
+func importMain(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	setUsage(fs, subcommands["import"].usage)
+	connFlags := addConnectionFlags(fs)
+	im := &importer{}
+	fs.StringVar(&im.space, "space", "", "The space to import into")
+	fs.StringVar(&im.tag, "tag", "", "The tag of the vertices")
+	fs.StringVar(&im.edge, "edge", "", "The edge type of the edges")
+	file := fs.String("file", "", "The CSV file with header, `-' for stdin")
+	fs.StringVar(&im.vidColumn, "vid-column", "vid", "The column of the vertex id")
+	fs.StringVar(&im.srcColumn, "src-column", "src", "The column of the edge source vertex id")
+	fs.StringVar(&im.dstColumn, "dst-column", "dst", "The column of the edge destination vertex id")
+	fs.StringVar(&im.rankColumn, "rank-column", "rank", "The column of the edge ranking, optional")
+	fs.IntVar(&im.batchSize, "batch-size", 100, "The rows inserted by one statement")
+	fs.IntVar(&im.rateLimit, "rate-limit", 0, "The max rows imported per second, 0 means no limit")
+	mappingFile := fs.String("mapping", "", "The JSON file mapping the source columns with transforms, see README")
+	fs.BoolVar(&im.skipValidation, "skip-validation", false, "Insert without validating all fields by the property types first")
+	fs.BoolVar(&im.dryRun, "dry-run", false, "Validate and generate the statements without executing, then print the batch plan")
+	fs.IntVar(&im.preview, "preview", 0, "Print the first N generated statements of --dry-run")
+	fs.StringVar(&im.emptyAs, "empty-as", emptyAsNull, "The empty field of the string properties is inserted as `null' or the `empty' string, NULL for the other types")
+	fs.Parse(args)
+
+	if im.space == "" || *file == "" || (im.tag == "") == (im.edge == "") || im.batchSize <= 0 || im.preview < 0 || (im.preview > 0 && !im.dryRun) ||
+		(im.emptyAs != emptyAsNull && im.emptyAs != emptyAsString) {
+		fs.Usage()
+		return exitUsageError
+	}
+
+	var err error
+	if *mappingFile != "" {
+		if im.mapping, err = loadMapping(*mappingFile); err != nil {
+			exitWith(exitFileError, "Load mapping %s failed, %s", *mappingFile, err.Error())
+		}
+	}
+	conn, err = connFlags.Connection()
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "%s", err.Error())
+	}
+	im.client, err = newSession(conn)
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "Fail to connect server, address: %s, username: %s, %s", conn.Address, conn.Username, err.Error())
+	}
+	defer im.client.Disconnect()
+
+	if resp, err := im.client.Execute("USE " + quoteName(im.space)); err != nil {
+		log.Printf("Use space %s failed, %s", im.space, err.Error())
+		return exitExecuteError
+	} else if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		log.Printf("Use space %s failed, %s", im.space, errorString(resp))
+		return exitStatementError
+	}
+	im.intVid = isIntVidSpace(im.client)
+	if err = im.describe(); err != nil {
+		log.Printf("%s", err.Error())
+		return errorExitCode(err, exitStatementError)
+	}
+
+	var r io.Reader = os.Stdin
+	if *file != "-" {
+		fd, err := os.Open(*file)
+		if err != nil {
+			exitWith(exitFileError, "Open file %s failed, %s", *file, err.Error())
+		}
+		defer fd.Close()
+		r = fd
+	}
+	start := time.Now()
+	im.progress = newDashboard("rows")
+	imported, failed, err := im.run(r)
+	im.progress.close()
+	if im.dryRun {
+		im.printPlan()
+		fmt.Printf("Dry run, nothing imported, generated in %s.", time.Since(start).Round(time.Millisecond))
+	} else {
+		fmt.Printf("Imported %d rows, failed %d rows in %s.", imported, failed, time.Since(start).Round(time.Millisecond))
+	}
+	fmt.Println()
+	if err != nil {
+		fmt.Printf("[ERROR] %s", err.Error())
+		fmt.Println()
+		return exitExecuteError
+	}
+	if failed > 0 {
+		return exitStatementError
+	}
+	return 0
+}
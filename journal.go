@@ -0,0 +1,115 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The append-only journal file opened by `:journal on <dir>`
+var journal *os.File
+
+// One JSON line per executed statement
+type journalEntry struct {
+	Time       string `json:"time"`
+	Space      string `json:"space"`
+	Statement  string `json:"statement"`
+	ErrorCode  int64  `json:"error_code"`
+	LatencyUs  int32  `json:"latency_in_us"`
+	ResultHash string `json:"result_sha256"`
+}
+
+// :journal on <dir> | :journal off
+func journalCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		if journal == nil {
+			fmt.Println("Journal is off.")
+		} else {
+			fmt.Printf("Journal is on, writing to %s.", journal.Name())
+			fmt.Println()
+		}
+		return nil
+	}
+	switch strings.ToLower(fields[0]) {
+	case "on":
+		if len(fields) != 2 {
+			return fmt.Errorf("Usage: :journal on <dir>")
+		}
+		if err := os.MkdirAll(fields[1], 0755); err != nil {
+			return err
+		}
+		file := filepath.Join(fields[1], fmt.Sprintf("journal-%s.jsonl", time.Now().Format("20060102150405")))
+		fd, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		closeJournal()
+		journal = fd
+		fmt.Printf("Journal to %s.", file)
+		fmt.Println()
+	case "off":
+		closeJournal()
+	default:
+		return fmt.Errorf("Usage: :journal on <dir> | :journal off")
+	}
+	return nil
+}
+
+func closeJournal() {
+	if journal != nil {
+		journal.Close()
+		journal = nil
+	}
+}
+
+// The content hash of the result tables
+func resultHash(resp *graph.ExecutionResponse) string {
+	h := sha256.New()
+	for _, table := range resp.GetData() {
+		io.WriteString(h, strings.Join(columnNames(table), "\x00"))
+		io.WriteString(h, "\n")
+		for _, row := range table.GetRows() {
+			io.WriteString(h, rowKey(row))
+			io.WriteString(h, "\n")
+		}
+		io.WriteString(h, "\x1e") // table separator
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func journalRecord(stmt string, resp *graph.ExecutionResponse) {
+	if journal == nil {
+		return
+	}
+	entry := journalEntry{
+		Time:       time.Now().Format(time.RFC3339Nano),
+		Space:      string(resp.SpaceName),
+		Statement:  redactStatement(stmt),
+		ErrorCode:  int64(resp.GetErrorCode()),
+		LatencyUs:  resp.GetLatencyInUs(),
+		ResultHash: resultHash(resp),
+	}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	if _, err = journal.Write(append(line, '\n')); err != nil {
+		fmt.Printf("[WARNING] Write journal failed, %s", err.Error())
+		fmt.Println()
+	}
+}
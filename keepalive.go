@@ -0,0 +1,83 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// Ping the session in the interval while waiting for the input, 0 to disable
+var keepaliveInterval time.Duration
+
+func durationSetting(v *time.Duration) setting {
+	return setting{
+		func() string { return v.String() },
+		func(s string) error {
+			d, err := time.ParseDuration(s)
+			if err != nil || d < 0 {
+				return fmt.Errorf("Expect a non-negative duration like 5m, got `%s'", s)
+			}
+			*v = d
+			return nil
+		},
+	}
+}
+
+// The pinger while idle, the session is used exclusively until stopped
+type keepalive struct {
+	client *Session
+	stopCh chan struct{}
+	done   chan struct{}
+	// The reconnection done while idle, reported after the input
+	notice string
+}
+
+// Returns nil if disabled
+func startKeepalive(client *Session) *keepalive {
+	if keepaliveInterval <= 0 {
+		return nil
+	}
+	k := &keepalive{client, make(chan struct{}), make(chan struct{}), ""}
+	interval := keepaliveInterval
+	client.background = true
+	go func() {
+		defer close(k.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-k.stopCh:
+				return
+			case <-ticker.C:
+			}
+			// The expired session is reconnected by Execute, reconnect the broken connection here
+			if _, err := ping(client); err != nil {
+				if e := client.Reconnect(); e != nil {
+					k.notice = fmt.Sprintf("Keepalive ping failed, %s, reconnect failed, %s", err.Error(), e.Error())
+				} else {
+					k.notice = fmt.Sprintf("Keepalive ping failed, %s, reconnected to %s", err.Error(), client.conn.Address)
+				}
+			}
+		}
+	}()
+	return k
+}
+
+// Stop and wait the in-flight ping
+func (k *keepalive) stop() {
+	if k == nil {
+		return
+	}
+	close(k.stopCh)
+	<-k.done
+	k.client.background = false
+	if k.notice != "" {
+		fmt.Printf("[NOTICE] %s.", k.notice)
+		fmt.Println()
+	}
+}
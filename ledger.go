@@ -0,0 +1,111 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The statements applied successfully by the batch run, rerunning the script
+// with the same ledger skips them, so the failed run is resumed without double-applying
+type ledger struct {
+	file    *os.File
+	applied map[string]bool
+	// The script of the statements, the ids are independent of the other scripts
+	script string
+	// The occurrences of the same statement in the script, to distinguish the repeated INSERTs
+	seen map[string]int
+}
+
+var stmtLedger *ledger
+
+// Each line is `<id> <time> <statement>`, only the id matters
+func openLedger(path string) (*ledger, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	l := &ledger{file: file, applied: map[string]bool{}, seen: map[string]int{}}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) > 0 {
+			l.applied[fields[0]] = true
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// Count the occurrences from the beginning of the script, by the base name to survive the moved directory
+func (l *ledger) beginScript(path string) {
+	l.script = filepath.Base(path)
+	l.seen = map[string]int{}
+}
+
+// The deterministic id of the statement by the script, its text and occurrence in the script
+func (l *ledger) id(stmt string) string {
+	stmt = strings.TrimSpace(stmt)
+	l.seen[stmt]++
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", l.script, l.seen[stmt], stmt)))
+	return hex.EncodeToString(sum[:])
+}
+
+// The session state like the space is lost by the rerun, so USE is replayed instead of skipped
+func ledgered(stmt string) bool {
+	return !usePattern.MatchString(stmt)
+}
+
+// The USE of any statement of the multiple ones, e.g. `USE s; INSERT ...`
+var useClausePattern = regexp.MustCompile(`(?is)(?:^|;)\s*(USE\s+[^;\s]+)`)
+
+// Replay the last USE of the statement skipped by the ledger, so the later statements run in the space they expect
+func replayUse(client *Session, stmt string) error {
+	m := useClausePattern.FindAllStringSubmatch(stmt, -1)
+	if len(m) == 0 {
+		return nil
+	}
+	use := m[len(m)-1][1]
+	resp, err := client.Execute(use)
+	if err != nil {
+		return fmt.Errorf("Replay `%s' of the skipped statement failed, %s", use, err.Error())
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return fmt.Errorf("Replay `%s' of the skipped statement failed, %s", use, errorString(resp))
+	}
+	return nil
+}
+
+// Flushed to the disk before the next statement, a crash never loses the applied one
+func (l *ledger) record(id string, stmt string) error {
+	oneLine := strings.Join(strings.Fields(redactStatement(stmt)), " ")
+	if _, err := fmt.Fprintf(l.file, "%s %s %s\n", id, time.Now().Format(time.RFC3339), oneLine); err != nil {
+		return err
+	}
+	l.applied[id] = true
+	return l.file.Sync()
+}
+
+func closeLedger() {
+	if stmtLedger != nil {
+		stmtLedger.file.Close()
+		stmtLedger = nil
+	}
+}
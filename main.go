@@ -7,23 +7,29 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 	"time"
 	"path/filepath"
 
-	ngdb "github.com/shylock-hg/nebula-go2.0"
 	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
 )
 
 const NebulaLabel = "Nebula-Console"
 const Version = "v2.0.0-alpha"
 
+// Suppress the banners, the footers and the time spent, only the results are printed, by `--quiet`
+var quiet = false
+
 func welcome(interactive bool) {
-	if !interactive {
+	if !interactive || quiet {
 		return;
 	}
 	fmt.Printf("Welcome to Nebula Graph %s!", Version)
@@ -31,7 +37,7 @@ func welcome(interactive bool) {
 }
 
 func bye(username string, interactive bool) {
-	if !interactive {
+	if !interactive || quiet {
 		return;
 	}
 	fmt.Printf("Bye %s!", username)
@@ -47,113 +53,863 @@ func clientCmd(query string) bool {
 	return false
 }
 
+// Console command begin with `:`, e.g. `:export file.csv SHOW HOSTS`
+type consoleCommand func(client *Session, c Cli, args string) error
+
+var consoleCommands = map[string]consoleCommand{
+	"export": exportCmd,
+	"format": formatCmd,
+	"foreach": foreachCmd,
+	"connect": connectCmd,
+	"disconnect": disconnectCmd,
+	"sessions": sessionsCmd,
+	"compare": compareCmd,
+	"pager": pagerCmd,
+	"journal": journalCmd,
+	"set": setCmd,
+	"unset": unsetCmd,
+	"dedup": dedupCmd,
+	"wizard": wizardCmd,
+	"template": templateCmd,
+	"quote": quoteCmd,
+	"ping": pingCmd,
+	"tee": teeCmd,
+	"notee": noteeCmd,
+	"view": viewCmd,
+	"schedule": scheduleCmd,
+	"reflow": reflowCmd,
+	"history": historyCmd,
+	"paste-insert": pasteInsertCmd,
+	"errhelp": errhelpCmd,
+	"watch": watchCmd,
+	"env": envCmd,
+	"batch": batchCmd,
+	"graph-stats": graphStatsCmd,
+	"expand": expandCmd,
+	"viz": vizCmd,
+	"timeout": timeoutCmd,
+	"use": useCmd,
+	"edit": editCmd,
+	"async": asyncCmd,
+	"jobs": jobsCmd,
+	"result": resultCmd,
+	"grammar": grammarCmd,
+	"syntax": syntaxCmd,
+	"show": showCmd,
+	"bookmark": bookmarkCmd,
+	"plans": plansCmd,
+	"on-host": onHostCmd,
+	"let": letCmd,
+}
+
+// Output format of the results
+const (
+	formatTable    = "table"
+	formatVertical = "vertical"
+	formatMarkdown = "markdown"
+	formatDot      = "dot"
+	formatHTML     = "html"
+	formatTSV      = "tsv"
+	formatNDJSON   = "ndjson"
+)
+
+var outputFormats = []string{formatTable, formatVertical, formatMarkdown, formatDot, formatHTML, formatTSV, formatNDJSON}
+
+// The formats consumed by the programs, without the time spent and timestamp
+func machineFormat(format string) bool {
+	return format == formatDot || format == formatHTML || format == formatTSV || format == formatNDJSON
+}
+
+// The errors and the notices are written to stderr instead of w for the machine format,
+// to not be mixed with its output, e.g. redirected to the file
+func noticeWriter(format string, w io.Writer) io.Writer {
+	if machineFormat(format) {
+		return os.Stderr
+	}
+	return w
+}
+
+var outputFormat = formatTable
+
+// :format table|vertical|markdown
+func formatCmd(client *Session, c Cli, args string) error {
+	f := strings.ToLower(strings.TrimSpace(args))
+	switch {
+	case f == "":
+		fmt.Printf("Output format is %s.", outputFormat)
+		fmt.Println()
+	case contains(outputFormats, f):
+		outputFormat = f
+	default:
+		return fmt.Errorf("Unknown format `%s', expect %s", args, strings.Join(outputFormats, ", "))
+	}
+	return nil
+}
+
+// The statement ends with `\G` is displayed vertically like MySQL
+func splitFormat(query string) (string, string) {
+	trimmed := strings.TrimSpace(query)
+	if strings.HasSuffix(trimmed, `\G`) {
+		return strings.TrimSuffix(trimmed, `\G`), formatVertical
+	}
+	return query, outputFormat
+}
+
+// return , is console command
+func consoleCmd(client *Session, c Cli, query string) (bool, error) {
+	plain := strings.TrimSpace(query)
+	if strings.HasPrefix(plain, "!") {
+		return true, shellEscape(plain[1:])
+	}
+	if plain == `\e` {
+		return true, editCmd(client, c, "")
+	}
+	if !strings.HasPrefix(plain, ":") {
+		return false, nil
+	}
+	fields := strings.SplitN(plain[1:], " ", 2)
+	cmd, ok := consoleCommands[strings.ToLower(fields[0])]
+	if !ok {
+		return true, fmt.Errorf("Unknown console command `%s'", fields[0])
+	}
+	args := ""
+	if len(fields) > 1 {
+		args = fields[1]
+	}
+	return true, cmd(client, c, args)
+}
+
+// Stop the batch run at the first failed statement, unless --continue-on-error
+var abortOnError = true
+var continueOnError = false
+
+// Exit the interactive console too on the execution (RPC) error or timeout, by `--abort-on-error` given explicitly
+var abortInteractive = false
+
+// The exit codes for the automation, see README
+const (
+	exitStatementError = 1 // nGQL statement failed
+	exitUsageError     = 2 // invalid flags or arguments
+	exitExecuteError   = 3 // execution (RPC) error or timeout
+	exitConnectError   = 4 // can't connect to the server
+	exitAuthError      = 5 // authentication failed
+	exitFileError      = 6 // the script, configuration or ledger file not found or unreadable
+	exitInterrupted    = 130
+)
+
+// Log and exit with the code
+func exitWith(code int, format string, args ...interface{}) {
+	log.Printf(format, args...)
+	os.Exit(code)
+}
+
+// The error exiting by the code, e.g. reading the password from stdin not a terminal is the usage error
+type codedError struct {
+	code int
+	err  error
+}
+
+func (e codedError) Error() string {
+	return e.err.Error()
+}
+
+// The code of the coded error, otherwise the default one
+func errorExitCode(err error, code int) int {
+	var coded codedError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	return code
+}
+
+// Exit on Ctrl+C of the -e/-f run, the interactive console handles it by itself
+func exitOnInterrupt() {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	go func() {
+		<-interrupt
+		fmt.Fprintln(os.Stderr, "[INTERRUPTED]")
+		os.Exit(exitInterrupted)
+	}()
+}
+
+var exitCode = 0
+
+var errAbort = errors.New("Aborted on error")
+
+// Record the failure of the batch run, returns errAbort to stop the run
+func batchFailed(c Cli, code int) error {
+	if c.Interactive() {
+		return nil
+	}
+	if code > exitCode {
+		exitCode = code
+	}
+	if continueOnError || !abortOnError {
+		return nil
+	}
+	return errAbort
+}
+
+// The execution (RPC) error or timeout, which exits the interactive console by `--abort-on-error`
+func executeFailed(c Cli) error {
+	if c.Interactive() && abortInteractive {
+		exitCode = exitExecuteError
+		return errAbort
+	}
+	return batchFailed(c, exitExecuteError)
+}
+
 var t = NewTable(2, "=", "-", "|")
 
-func printResp(resp *graph.ExecutionResponse, duration time.Duration) {
+// The symbolic error code and the message of the failed response
+func errorString(resp *graph.ExecutionResponse) string {
+	str := resp.GetErrorCode().String()
+	if msg := string(resp.GetErrorMsg()); msg != "" {
+		str += ": " + msg
+	}
+	return str
+}
+
+// The plan table is rendered as the tree if the statement returnsPlan
+func printData(table *graph.DataSet, format string, explained bool) {
+	switch format {
+	case formatVertical:
+		t.PrintVertical(table)
+	case formatMarkdown:
+		t.PrintMarkdown(table)
+	case formatDot:
+		t.PrintDot(table)
+	case formatHTML:
+		t.PrintHTML(table)
+	case formatTSV:
+		t.PrintTSV(table)
+	case formatNDJSON:
+		t.PrintNDJSON(table)
+	default:
+		if explained && isPlanTable(table) {
+			t.PrintPlan(table)
+		} else {
+			t.PrintTable(table)
+		}
+	}
+}
+
+// The notice of the rows beyond max_rows, suppressed by quiet
+func printMoreRows(format string, more int) {
+	if more == 0 || quiet {
+		return
+	}
+	w := noticeWriter(format, out)
+	fmt.Fprintf(w, "… %d more rows, show all by `:set max_rows 0' then `:show last'", more)
+	fmt.Fprintln(w)
+}
+
+func printResp(resp *graph.ExecutionResponse, duration time.Duration, format string, explained bool) {
+	if wait := startPager(respLines(resp, format)); wait != nil {
+		defer wait()
+	}
 	// Error
 	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
-		fmt.Printf("[ERROR (%d)]", resp.GetErrorCode())
-		fmt.Println()
+		w := noticeWriter(format, out)
+		fmt.Fprint(w, errorColor(fmt.Sprintf("[ERROR (%d)] %s", resp.GetErrorCode(), errorString(resp))))
+		fmt.Fprintln(w)
 		return
 	}
 	// Show tables
-	if resp.GetData() != nil {
+	resultSpace = string(resp.SpaceName)
+	if format == formatHTML {
+		// One document of all the results
+		shown := make([]*graph.DataSet, 0, len(resp.GetData()))
 		for _, table := range resp.GetData() {
-			t.PrintTable(table)
+			table, more := limitRows(table)
+			shown = append(shown, table)
+			printMoreRows(format, more)
+		}
+		if len(shown) > 0 {
+			t.PrintHTML(shown...)
+		}
+	} else if resp.GetData() != nil {
+		for i, table := range resp.GetData() {
+			// Labeled if multiple, re-rendered by `:show last <n>`
+			if len(resp.GetData()) > 1 && !machineFormat(format) && !quiet {
+				fmt.Fprintf(out, "Result %d/%d", i+1, len(resp.GetData()))
+				fmt.Fprintln(out)
+			}
+			shown, more := limitRows(table)
+			printData(shown, format, explained)
+			printMoreRows(format, more)
 		}
 	}
 	// Show time
-	fmt.Printf("time spent %d/%d us", resp.GetLatencyInUs(), duration/*ns*//1000)
-	fmt.Println()
+	if machineFormat(format) || quiet {
+		return
+	}
+	fmt.Fprintf(out, "time spent %d/%d us", resp.GetLatencyInUs(), duration/*ns*//1000)
+	if showResourceUsage {
+		fmt.Fprint(out, resourceUsage(resp, explained))
+	}
+	fmt.Fprintln(out)
+}
+
+// The whole line comment of the scripts begins with `--` or `#`
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "--") || strings.HasPrefix(trimmed, "#")
+}
+
+// The statement is terminated by `;` or `\G` at the end of line
+func isTerminated(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasSuffix(trimmed, ";") || strings.HasSuffix(trimmed, `\G`)
+}
+
+// Returns errAbort if the batch run should stop
+func execute(client *Session, c Cli, query string) error {
+	// Asked when buffered, not when committed
+	if !confirmDestructive(c, query) {
+		fmt.Println("[CANCELLED]")
+		fmt.Println()
+		return nil
+	}
+	if bufferStatement(query) {
+		return nil
+	}
+	_, err := runStatement(client, c, query)
+	return err
+}
+
+// Execute the statement confirmed already, e.g. by `:batch commit`, succeeded is false if failed,
+// the statement applied already by the ledger succeeded, returns errAbort if the batch run should stop
+func runStatement(client *Session, c Cli, query string) (succeeded bool, err error) {
+	stmt, format := splitFormat(query)
+	ew := noticeWriter(format, os.Stdout)
+	stmt, err = substituteVariables(stmt)
+	if err != nil {
+		if !c.Interactive() {
+			notify("batch", stmt, "", err.Error())
+		}
+		reportStatement(stmt, "variable error", 0, 0)
+		fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+		fmt.Fprintln(ew)
+		c.SetisErr(true)
+		fmt.Println()
+		return false, batchFailed(c, exitStatementError)
+	}
+	ledgerID := ""
+	if stmtLedger != nil && !c.Interactive() && ledgered(stmt) {
+		ledgerID = stmtLedger.id(stmt)
+		if stmtLedger.applied[ledgerID] {
+			if err = replayUse(client, stmt); err != nil {
+				fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+				fmt.Fprintln(ew)
+				c.SetisErr(true)
+				fmt.Println()
+				return false, batchFailed(c, exitStatementError)
+			}
+			c.SetSpace(client.space)
+			reportStatement(stmt, "skipped", 0, 0)
+			fmt.Printf("[SKIPPED] Applied already by the ledger, %s", ledgerID[:12])
+			fmt.Println()
+			fmt.Println()
+			return true, nil
+		}
+	}
+	stmt = autoQuoteVids(client, stmt)
+	start := time.Now()
+	var resp *graph.ExecutionResponse
+	scriptProgress.begin()
+	if c.Interactive() {
+		resp, err = client.ExecuteInterruptible(stmt)
+	} else {
+		resp, err = client.Execute(stmt)
+	}
+	duration := time.Since(start)
+	lastStatus.code, lastStatus.name, lastStatus.elapsed = "", "", duration
+	switch {
+	case errors.Is(err, errTimeout):
+		lastStatus.name = "TIMEOUT"
+	case err != nil:
+		lastStatus.name = "RPC_ERROR"
+	case resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED:
+		lastStatus.code = strconv.FormatInt(int64(resp.GetErrorCode()), 10)
+		lastStatus.name = resp.GetErrorCode().String()
+	}
+	if err != nil || resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		scriptProgress.end(0, 1)
+	} else {
+		scriptProgress.end(1, 0)
+	}
+	if errors.Is(err, errInterrupted) {
+		fmt.Println("[INTERRUPTED]")
+		if e := reconnectFailure(err); e != nil {
+			fmt.Fprint(ew, errorColor(fmt.Sprintf("[RPC ERROR] Reconnect to %s failed, %s", client.conn.Address, e.Error())))
+			fmt.Fprintln(ew)
+		}
+		fmt.Println()
+		return false, nil
+	}
+	if errors.Is(err, errTimeout) {
+		// The statement may be still running in the server
+		reportStatement(stmt, "timeout", 0, duration)
+		if e := reconnectFailure(err); e != nil {
+			fmt.Fprint(ew, errorColor(fmt.Sprintf("[TIMEOUT] Abandoned after %s, reconnect to %s failed, %s", queryTimeout, client.conn.Address, e.Error())))
+		} else {
+			fmt.Fprint(ew, errorColor(fmt.Sprintf("[TIMEOUT] Abandoned after %s, reconnected to %s.", queryTimeout, client.conn.Address)))
+		}
+		fmt.Fprintln(ew)
+		c.SetisErr(true)
+		fmt.Println()
+		return false, executeFailed(c)
+	}
+	if err != nil {
+		// Exception, e.g. the RPC failure
+		if !c.Interactive() {
+			notify("batch", stmt, "", err.Error())
+		}
+		reportStatement(stmt, "rpc error", 0, duration)
+		fmt.Fprint(ew, errorColor(fmt.Sprintf("[RPC ERROR] Execute error, %s, reconnecting to %s.", err.Error(), client.conn.Address)))
+		fmt.Fprintln(ew)
+		if err = client.Reconnect(); err != nil {
+			fmt.Fprint(ew, errorColor(fmt.Sprintf("[RPC ERROR] Reconnect failed, %s", err.Error())))
+			fmt.Fprintln(ew)
+		}
+		c.SetisErr(true)
+		fmt.Println()
+		return false, executeFailed(c)
+	}
+	if !c.Interactive() && resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		notify("batch", stmt, resp.GetErrorCode().String(), string(resp.GetErrorMsg()))
+	}
+	if ledgerID != "" && resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED {
+		if err = stmtLedger.record(ledgerID, stmt); err != nil {
+			exitWith(exitFileError, "Record the ledger failed, %s", err.Error())
+		}
+	}
+	// The evidence of the whole result returned by the server, before truncated by the budget
+	journalRecord(stmt, resp)
+	if dropped := limitResultMemory(resp); dropped > 0 {
+		fmt.Fprintf(ew, "[WARNING] The result exceeds the memory budget %s, %d rows truncated.",
+			formatByteSize(maxResultMemory), dropped)
+		fmt.Fprintln(ew)
+	}
+	reportStatement(stmt, respStatus(resp), resp.GetLatencyInUs(), duration)
+	teeStatement(stmt)
+	cacheResp(resp, returnsPlan(stmt))
+	printResp(resp, duration, format, returnsPlan(stmt))
+	if useBanner && !quiet && resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED && usePattern.MatchString(stmt) {
+		printSpaceBanner(client, string(resp.SpaceName))
+	}
+	if !machineFormat(format) && !quiet {
+		fmt.Fprintln(out, time.Now().Format("2006-01-02 15:04:05"))
+	}
+	// Tracked by the session, not stale after the failed statements
+	c.SetSpace(client.space)
+	rememberSpace(client)
+	c.SetisErr(resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED)
+	if !quiet {
+		fmt.Println()
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		activeTutorial.executed(stmt, nil)
+		return false, batchFailed(c, exitStatementError)
+	}
+	activeTutorial.executed(stmt, resp)
+	return true, nil
+}
+
+// Print the statements of the batch run before the results by `--echo`
+var echoStatements = false
+
+// The line read from the script, prefixed by the line number, redacted if of the secret statement
+func echoLine(c Cli, stmt string, line string) {
+	numbered, ok := c.(interface{ Line() int })
+	if !ok || c.Interactive() {
+		return
+	}
+	if isSecretStatement(stmt + line) {
+		line = redactLine(line)
+	}
+	fmt.Fprintf(out, "%d: %s", numbered.Line(), line)
+	fmt.Fprintln(out)
 }
 
 // Loop the request util fatal or timeout
-// We treat one line as one query
-// Add line break yourself as `SHOW \<CR>HOSTS`
-func loop(client *ngdb.GraphClient, c Cli) error {
+// The statement is accumulated line by line until terminated by `;`
+// The client side commands are always one line
+func loop(client *Session, c Cli) error {
+	stmt := ""
+	// Only the statements entered interactively are recorded, excluding the sourced ones
+	_, recordable := c.(*iCli)
 	for true {
+		ew := noticeWriter(outputFormat, os.Stdout)
+		// Keep the interactive session alive while waiting for the input
+		var idle *keepalive
+		if recordable {
+			reloadGrammarIfChanged()
+			idle = startKeepalive(client)
+		}
 		line, err, exit := c.ReadLine()
+		idle.stop()
 		lineString := string(line)
 		if  exit {
+			// The last statement without terminator of the script, the half-typed one is dropped
+			// by Ctrl+C or Ctrl+D at the prompt instead
+			if err == nil && !recordable && strings.TrimSpace(stmt) != "" {
+				err = execute(client, c, stmt)
+			}
+			if err == nil {
+				err = unterminatedBatch(c)
+			}
 			return err
 		}
-		if len(line) == 0 {
-			fmt.Println()
+		if isCommentLine(lineString) {
+			// Not sent to the server, which errors on the comment-only statement
 			continue
 		}
+		if echoStatements && (stmt != "" || strings.TrimSpace(lineString) != "") {
+			echoLine(c, stmt, lineString)
+		}
+		if stmt == "" {
+			if len(strings.TrimSpace(lineString)) == 0 {
+				if c.Interactive() {
+					fmt.Println()
+				}
+				continue
+			}
+
+			// Recall the history by `!!` or `!N`, then through the same pipeline as typed
+			if recalled, isRecall, err := stmtHistory.recall(lineString); isRecall {
+				if err != nil {
+					fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+					fmt.Fprintln(ew)
+					c.SetisErr(true)
+					fmt.Println()
+					continue
+				}
+				fmt.Println(recalled)
+				lineString = recalled
+			}
+			if fetch, space, isLink, err := linkStatement(client, lineString); isLink {
+				if err != nil {
+					fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+					fmt.Fprintln(ew)
+					c.SetisErr(true)
+					fmt.Println()
+					continue
+				}
+				fmt.Println(fetch)
+				if space != "" {
+					if recordable {
+						stmtHistory.record(fetch)
+					}
+					if err = fetchLink(client, c, space, fetch); err == errAbort {
+						return err
+					} else if err != nil {
+						fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+						fmt.Fprintln(ew)
+						c.SetisErr(true)
+						fmt.Println()
+					}
+					continue
+				}
+				lineString = fetch
+			}
+			var handled bool
+			if lineString, handled = activeTutorial.command(lineString); handled {
+				continue
+			}
 
-		// Client side command
-		if clientCmd(lineString) {
-			// Quit
-			return nil
+			// Client side command
+			if clientCmd(lineString) {
+				// Quit
+				return nil
+			}
+			if isCmd, err := consoleCmd(client, c, lineString); isCmd {
+				// The edited statement is recorded instead
+				if recordable && !isEditCommand(lineString) {
+					stmtHistory.record(lineString)
+				}
+				if err == errAbort {
+					// The sourced script failed
+					return err
+				}
+				if err == errCancelled {
+					fmt.Println("[CANCELLED]")
+					fmt.Println()
+					continue
+				}
+				if err != nil {
+					fmt.Fprint(ew, errorColor(fmt.Sprintf("[ERROR] %s", err.Error())))
+					fmt.Fprintln(ew)
+				}
+				c.SetisErr(err != nil)
+				fmt.Println()
+				// The failed command fails the batch run like the statement, e.g. `:export`
+				if err != nil {
+					if err = batchFailed(c, exitStatementError); err != nil {
+						return err
+					}
+				}
+				continue
+			}
 		}
 
-		start := time.Now()
-		resp, err := client.Execute(lineString)
-		duration := time.Since(start)
-		if err != nil {
-			// Exception
-			log.Fatalf("Execute error, %s", err.Error())
+		stmt += lineString + "\n"
+		if !isTerminated(lineString) {
+			c.SetContinue(true)
+			continue
 		}
-		printResp(resp, duration)
-		fmt.Println(time.Now().Format("2006-01-02 15:04:05"))
-		c.SetSpace(string(resp.SpaceName))
-		c.SetisErr(resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED)
-		fmt.Println()
+		c.SetContinue(false)
+		if recordable {
+			stmtHistory.record(stmt)
+		}
+		if err = execute(client, c, stmt); err != nil {
+			return err
+		}
+		stmt = ""
 	}
 	return nil
 }
 
-func main() {
-	address := flag.String("address", "127.0.0.1", "The Nebula Graph IP address")
-	port := flag.Int("port", 3699, "The Nebula Graph Port")
-	username := flag.String("u", "user", "The Nebula Graph login user name")
-	password := flag.String("p", "password", "The Nebula Graph login password")
-	script := flag.String("e", "", "The nGQL directly")
-	file := flag.String("f", "", "The nGQL script file name")
-	flag.Parse()
-
-	interactive := *script == "" && *file == ""
-
-	historyHome := os.Getenv("HOME")
-	if historyHome == "" {
+// The directory of the history, configuration and state files
+func consoleHome() (string, error) {
+	home := os.Getenv("HOME")
+	if home == "" {
 		ex, err := os.Executable()
 		if err != nil {
-			log.Fatalf("Get executable failed: %s", err.Error())
+			return "", fmt.Errorf("Get executable failed: %s", err.Error())
+		}
+		home = filepath.Dir(ex)  // Set to executable folder
+	}
+	return home, nil
+}
+
+func main() {
+	if len(os.Args) > 1 {
+		if sub, ok := subcommands[os.Args[1]]; ok {
+			os.Exit(sub.run(os.Args[2:]))
+		}
+	}
+	// The bare invocation accepts the flags of all modes for the backward compatibility
+	os.Exit(runConsole("", os.Args[1:]))
+}
+
+// Run the console in the mode, repl, exec, bench or all of them for the bare invocation,
+// returns the exit code after disconnecting
+func runConsole(mode string, args []string) int {
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	if mode == "" {
+		fs.Usage = func() { printUsage(fs) }
+	} else {
+		setUsage(fs, subcommands[mode].usage)
+	}
+	connFlags := addConnectionFlags(fs)
+	script, file := new(string), new(string)
+	ledgerFile, reportFile := new(string), new(string)
+	watch := new(time.Duration)
+	if mode == "" || mode == "exec" {
+		script = fs.String("e", "", "The nGQL directly")
+		file = fs.String("f", "", "The nGQL script file name")
+		fs.BoolVar(&abortOnError, "abort-on-error", true, "Stop the -e/-f run at the first failed statement (default), "+
+			"given explicitly also exits the interactive console on the execution error")
+		fs.BoolVar(&continueOnError, "continue-on-error", false, "Continue the -e/-f run after the failed statements, the exit code still reflects the failure")
+		ledgerFile = fs.String("ledger", "", "Record the applied statements of -e/-f to the file, rerun skips them to resume without double-applying")
+		fs.BoolVar(&echoStatements, "echo", false, "Print each statement of -e/-f prefixed by its line number before the result")
+		fs.BoolVar(&quiet, "quiet", false, "Print the results only, without the banners, the row counts, the time spent and the timestamps")
+		fs.BoolVar(&quiet, "q", false, "Shorthand of --quiet")
+		watch = fs.Duration("watch", 0, "Re-execute the statement of -e in the interval like 5s, clearing the screen, until Ctrl+C")
+		reportFile = fs.String("report", "", "Report the status, latency and wall time of each statement of -f to the CSV file, or - to print the table at the end")
+	}
+	bench, concurrency, probeInterval := new(int), new(int), new(time.Duration)
+	if mode == "" {
+		bench = fs.Int("bench", 0, "Execute the statement of -e N times and report the latency")
+	} else if mode == "bench" {
+		bench = fs.Int("n", 100, "Execute the statement N times")
+	}
+	if mode == "" || mode == "bench" {
+		concurrency = fs.Int("concurrency", 1, "The concurrent sessions of the benchmark")
+		probeInterval = fs.Duration("probe-interval", 10*time.Second, "Probe the benchmark sessions by ping in the interval and replace the broken ones, 0 to disable")
+	}
+	fs.Var(paramFlag{}, "param", "Set the variable referenced by ${name} in statements, e.g. --param vid=player100, repeatable")
+	color := fs.String("color", "auto", "Color the values by type, auto(only if stdout is a terminal), always or never")
+	timezone := fs.String("timezone", "", "Convert the datetime values to the zone, e.g. Asia/Shanghai or Local, default keeps the server one")
+	maxMemory := fs.String("max-result-memory", "0", "Truncate the decoded result beyond the memory budget like 512MB before rendering and caching, 0 means no limit")
+	recordFile, tutorial := new(string), new(bool)
+	if mode == "" || mode == "repl" {
+		recordFile = fs.String("record-session", "", "Record the interactive session to the file in the asciinema format, e.g. session.cast")
+		fs.StringVar(&promptTemplate, "prompt", "", "The prompt template like '{user}@{host}:{space}{err?!}> ', placeholders user, host, space, code, err, elapsed, {name?text} shows the text if not empty")
+		tutorial = fs.Bool("tutorial", false, "Guide the built-in lesson creating the sample NBA space, then GO, FETCH and MATCH, validating each step")
+		fs.DurationVar(&keepaliveInterval, "keepalive", 0, "Ping the server in the interval like 5m while the console is idle, 0 to disable")
+	}
+	fs.BoolVar(&forceDestructive, "force", false, "Execute DROP SPACE/TAG/EDGE and the unconstrained DELETE without confirmation")
+	fs.DurationVar(&queryTimeout, "query-timeout", 0, "Abandon the statement not responded in the duration like 30s, 0 means no limit")
+	format := fs.String("format", formatTable, "The output format, "+strings.Join(outputFormats, ", "))
+	fs.IntVar(&maxRows, "max-rows", 0, "Render the first N rows of each result only, 0 means no limit")
+	noCompletion := fs.Bool("no-completion", false, "Start without building the completion tree, e.g. the scripted interactive runs")
+	noHistory := fs.Bool("no-history", false, "Neither load nor save the history files, the statements are recalled in the session only")
+	fs.BoolVar(&htmlFragment, "html-fragment", false, "Print the <table> only for --format html, without the document around it")
+	configFile := fs.String("config", "", "The console configuration file, default ~/.nebula_console.json")
+	fs.Parse(args)
+	completionEnabled, historyEnabled = !*noCompletion, !*noHistory
+	fs.Visit(func(f *flag.Flag) {
+		abortInteractive = abortInteractive || (f.Name == "abort-on-error" && abortOnError)
+	})
+
+	switch mode {
+	case "exec":
+		// The statement as the arguments, e.g. `nebula-console exec 'SHOW HOSTS'`
+		if *script == "" && *file == "" && fs.NArg() > 0 {
+			*script = strings.Join(fs.Args(), " ")
+		}
+	case "bench":
+		*script = strings.Join(fs.Args(), " ")
+		if *script == "" || *bench <= 0 {
+			fs.Usage()
+			return exitUsageError
+		}
+	case "repl":
+		if !stdinIsTTY {
+			exitWith(exitUsageError, "The repl requires stdin to be a terminal, run the piped statements by `exec'")
+		}
+	}
+
+	// Read the statements from stdin without -e/-f if it's not a terminal, e.g. `cat load.ngql | nebula-console`
+	interactive := mode != "exec" && *script == "" && *file == "" && stdinIsTTY
+	if *tutorial && !interactive {
+		exitWith(exitUsageError, "The tutorial requires stdin to be a terminal")
+	}
+	if err := settings["color"].set(*color); err != nil {
+		exitWith(exitUsageError, "Invalid --color, %s", err.Error())
+	}
+	if err := formatCmd(nil, nil, *format); err != nil {
+		exitWith(exitUsageError, "Invalid --format, %s", err.Error())
+	}
+	if err := settings["timezone"].set(*timezone); err != nil {
+		exitWith(exitUsageError, "Invalid --timezone, %s", err.Error())
+	}
+	if err := settings["max_result_memory"].set(*maxMemory); err != nil {
+		exitWith(exitUsageError, "Invalid --max-result-memory, %s", err.Error())
+	}
+
+	var err error
+	conn, err = connFlags.Connection()
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "%s", err.Error())
+	}
+
+	if *bench > 0 {
+		if *script == "" {
+			exitWith(exitUsageError, "--bench requires the statement by -e")
 		}
-		historyHome = filepath.Dir(ex)  // Set to executable folder
+		return runBench(*script, *bench, *concurrency, *probeInterval)
+	}
+
+	historyHome, err := consoleHome()
+	if err != nil {
+		log.Fatalf("%s", err.Error())
+	}
+
+	if *configFile == "" {
+		*configFile = filepath.Join(historyHome, ".nebula_console.json")
+	}
+	c, err := loadConfig(*configFile)
+	if err != nil {
+		exitWith(exitFileError, "Load configuration %s failed, %s", *configFile, err.Error())
 	}
+	conf = c
+	loadState(filepath.Join(historyHome, ".nebula_console_state.json"))
+	envDir = filepath.Join(historyHome, ".nebula_console_env")
 
-	client, err := ngdb.NewClient(fmt.Sprintf("%s:%d", *address, *port))
+	client, err := newSession(conn)
 	if err != nil {
-		log.Fatalf("Fail to create client, address: %s, port: %d, %s", *address, *port, err.Error())
+		exitWith(errorExitCode(err, exitConnectError), "Fail to connect server, address: %s, username: %s, %s", conn.Address, conn.Username, err.Error())
 	}
 
-	if err = client.Connect(*username, *password); err != nil {
-		log.Fatalf("Fail to connect server, username: %s, password: %s, %s", *username, *password, err.Error())
+	sessions[defaultSession] = client
+	defer closeSessions()
+	defer closeJournal()
+	defer closeTee()
+	defer closeSchedules()
+	if *ledgerFile != "" && !interactive {
+		if stmtLedger, err = openLedger(*ledgerFile); err != nil {
+			log.Printf("Open ledger %s failed, %s", *ledgerFile, err.Error())
+			return exitFileError
+		}
+		defer closeLedger()
+	}
+	if *reportFile != "" && *file != "" {
+		stmtReport = &runReport{file: *reportFile}
 	}
 
+	if !interactive && *watch == 0 {
+		exitOnInterrupt()
+	}
+	pagerEnabled = interactive
+	if *recordFile != "" && interactive {
+		// Stopped after the bye message
+		if err = startRecording(*recordFile); err != nil {
+			log.Printf("Record the session to %s failed, %s", *recordFile, err.Error())
+			return exitFileError
+		}
+		defer closeRecording()
+	}
+
+	// Released at the prompt for the background messages
+	holdTerminal()
 	welcome(interactive)
 
-	defer bye(*username, interactive)
+	defer bye(conn.Username, interactive)
 	defer client.Disconnect()
 
 	// Loop the request
 	var exit error = nil
 	if interactive {
-		exit = loop(client, NewiCli(historyHome, *username))
+		completionsDir = filepath.Join(historyHome, ".nebula_console_completions")
+		grammarFile = filepath.Join(historyHome, ".nebula_console_grammar.json")
+		if err = reloadGrammar(); err != nil {
+			fmt.Printf("[WARNING] %s", err.Error())
+			fmt.Println()
+		}
+		icli := NewiCli(historyHome, conn.Username)
+		if historyEnabled {
+			loadHistory(filepath.Join(historyHome, ".nebula_history_statements"))
+		}
+		offerLastSpace(client, icli)
+		if *tutorial {
+			startTutorial()
+		}
+		exit = loop(client, icli)
+	} else if *script != "" && *watch > 0 {
+		if exit = watchCmd(client, nil, fmt.Sprintf("--interval %s %s", *watch, *script)); exit != nil {
+			fmt.Print(errorColor(fmt.Sprintf("[ERROR] %s", exit.Error())))
+			fmt.Println()
+		}
 	} else if *script != "" {
 		exit = loop(client, NewnCli(strings.NewReader(*script)))
 	} else if *file != "" {
 		fd, err := os.Open(*file)
 		if err != nil {
-			log.Fatalf("Open file %s failed, %s", *file, err.Error())
+			log.Printf("Open file %s failed, %s", *file, err.Error())
+			return exitFileError
+		}
+		if !stdoutIsTTY {
+			// Not mixed with the output on the terminal
+			scriptProgress = newDashboard("statements")
+		}
+		if stmtLedger != nil {
+			stmtLedger.beginScript(*file)
 		}
-		exit = loop(client, NewnCli(fd))
+		exit = loop(client, NewnCli(scriptProgress.track(fd)))
+		scriptProgress.close()
 		fd.Close()
+	} else {
+		exit = loop(client, NewnCli(os.Stdin))
 	}
+	closeReport()
 
-	if exit != nil {
-		os.Exit(1)
+	if exit != nil && exit != errAbort && exitCode == 0 {
+		return 1
 	}
+	return exitCode
 }
@@ -0,0 +1,175 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// The target column of the import computed from the source CSV columns
+type columnMapping struct {
+	// The id column or the property name
+	Name string `json:"name"`
+	// The source column, default the same name
+	From string `json:"from"`
+	// Join the source columns instead, e.g. the composite vid
+	Concat    []string `json:"concat"`
+	Separator string   `json:"separator"`
+	// Applied in order: trim, lowercase, uppercase
+	Transforms []string `json:"transforms"`
+	// Parse the value by the Go layout like `01/02/2006', then format by the property type
+	DateLayout string `json:"date_layout"`
+	// The constant if the value is empty, or the whole column without source
+	Default string `json:"default"`
+}
+
+// The import mapping file in JSON, e.g. {"columns": [{"name": "vid", "concat": ["first", "last"], "separator": "_"}]}
+type importMapping struct {
+	Columns []columnMapping `json:"columns"`
+}
+
+var mappingTransforms = []string{"trim", "lowercase", "uppercase"}
+
+func loadMapping(file string) (*importMapping, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	m := &importMapping{}
+	if err = json.Unmarshal(content, m); err != nil {
+		return nil, err
+	}
+	if len(m.Columns) == 0 {
+		return nil, fmt.Errorf("No columns in the mapping")
+	}
+	for _, col := range m.Columns {
+		if col.Name == "" {
+			return nil, fmt.Errorf("Missing the name of the mapped column")
+		}
+		for _, transform := range col.Transforms {
+			if !contains(mappingTransforms, transform) {
+				return nil, fmt.Errorf("Unknown transform `%s' of column `%s', expect %s", transform, col.Name,
+					strings.Join(mappingTransforms, ", "))
+			}
+		}
+	}
+	return m, nil
+}
+
+// The layout to format the parsed time by the property type
+func dateOutputLayout(typ string) string {
+	switch typ {
+	case "date":
+		return "2006-01-02"
+	case "time":
+		return "15:04:05"
+	}
+	return "2006-01-02T15:04:05"
+}
+
+// The records reader, either the CSV reader or mapped from it
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// Map the source records to the target columns, the first record is the header
+type mappedReader struct {
+	reader  *csv.Reader
+	mapping *importMapping
+	schema  map[string]string
+	// The source indexes of each target column, empty for the constant
+	sources [][]int
+}
+
+func (r *mappedReader) readHeader() ([]string, error) {
+	header, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		index[name] = i
+	}
+	names := make([]string, len(r.mapping.Columns))
+	r.sources = make([][]int, len(r.mapping.Columns))
+	for i, col := range r.mapping.Columns {
+		names[i] = col.Name
+		from := col.Concat
+		if len(from) == 0 && (col.From != "" || col.Default == "") {
+			from = []string{col.Name}
+			if col.From != "" {
+				from = []string{col.From}
+			}
+		}
+		for _, source := range from {
+			j, ok := index[source]
+			if !ok {
+				return nil, fmt.Errorf("Source column `%s' of `%s' not found", source, col.Name)
+			}
+			r.sources[i] = append(r.sources[i], j)
+		}
+	}
+	return names, nil
+}
+
+func (r *mappedReader) value(col columnMapping, sources []int, record []string) string {
+	// The transforms apply to each source value before joined
+	parts := make([]string, 0, len(sources))
+	for _, j := range sources {
+		if j >= len(record) {
+			continue
+		}
+		part := record[j]
+		for _, transform := range col.Transforms {
+			switch transform {
+			case "trim":
+				part = strings.TrimSpace(part)
+			case "lowercase":
+				part = strings.ToLower(part)
+			case "uppercase":
+				part = strings.ToUpper(part)
+			}
+		}
+		parts = append(parts, part)
+	}
+	v := strings.Join(parts, col.Separator)
+	if v == "" {
+		return col.Default
+	}
+	if col.DateLayout != "" {
+		// Keep the unparsable value to be reported by the validation
+		if t, err := time.Parse(col.DateLayout, v); err == nil {
+			if typ := r.schema[col.Name]; typ == "timestamp" {
+				v = strconv.FormatInt(t.Unix(), 10)
+			} else {
+				v = t.Format(dateOutputLayout(typ))
+			}
+		}
+	}
+	return v
+}
+
+func (r *mappedReader) Read() ([]string, error) {
+	if r.sources == nil {
+		return r.readHeader()
+	}
+	record, err := r.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	mapped := make([]string, len(r.mapping.Columns))
+	for i, col := range r.mapping.Columns {
+		mapped[i] = r.value(col, r.sources[i], record)
+	}
+	return mapped, nil
+}
@@ -0,0 +1,153 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The result beyond the budget is truncated before rendering and caching, 0 means no limit,
+// the client decodes the whole response first so the peak memory isn't bounded
+var maxResultMemory int64 = 0
+
+// The approximate overhead of the value struct and its pointers
+const valueOverhead = 64
+
+var byteUnits = []struct {
+	suffix string
+	size   int64
+}{
+	{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1},
+}
+
+// Parse the size like 512MB, 1G or 1048576
+func parseByteSize(s string) (int64, error) {
+	upper := strings.ToUpper(strings.TrimSpace(s))
+	unit := int64(1)
+	for _, u := range byteUnits {
+		if strings.HasSuffix(upper, u.suffix) {
+			unit = u.size
+			upper = strings.TrimSpace(strings.TrimSuffix(upper, u.suffix))
+			break
+		}
+	}
+	n, err := strconv.ParseInt(upper, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("Expect the size like 512MB, got `%s'", s)
+	}
+	return n * unit, nil
+}
+
+func formatByteSize(n int64) string {
+	for _, u := range byteUnits[:3] {
+		if n >= u.size && n%u.size == 0 {
+			return fmt.Sprintf("%d%s", n/u.size, u.suffix)
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+func byteSizeSetting(v *int64) setting {
+	return setting{
+		func() string { return formatByteSize(*v) },
+		func(s string) error {
+			n, err := parseByteSize(s)
+			if err != nil {
+				return err
+			}
+			*v = n
+			return nil
+		},
+	}
+}
+
+func propsSize(props map[string]*common.Value) int64 {
+	size := int64(0)
+	for k, v := range props {
+		size += int64(len(k)) + valueSize(v)
+	}
+	return size
+}
+
+func vertexSize(vertex *common.Vertex) int64 {
+	if vertex == nil {
+		return 0
+	}
+	size := int64(valueOverhead + len(vertex.GetVid()))
+	for _, tag := range vertex.GetTags() {
+		size += int64(len(tag.GetName())) + propsSize(tag.GetProps())
+	}
+	return size
+}
+
+// The approximate memory of the decoded value
+func valueSize(value *common.Value) int64 {
+	size := int64(valueOverhead)
+	switch {
+	case value.IsSetSVal():
+		size += int64(len(value.GetSVal()))
+	case value.IsSetVVal():
+		size += vertexSize(value.GetVVal())
+	case value.IsSetEVal():
+		edge := value.GetEVal()
+		size += int64(len(edge.GetSrc())+len(edge.GetDst())+len(edge.GetName())) + propsSize(edge.GetProps())
+	case value.IsSetPVal():
+		path := value.GetPVal()
+		size += vertexSize(path.GetSrc())
+		for _, step := range path.GetSteps() {
+			size += vertexSize(step.GetDst()) + int64(len(step.GetName())) + propsSize(step.GetProps())
+		}
+	case value.IsSetLVal():
+		for _, v := range value.GetLVal().GetValues() {
+			size += valueSize(v)
+		}
+	case value.IsSetMVal():
+		size += propsSize(value.GetMVal().GetKvs())
+	case value.IsSetUVal():
+		for _, v := range value.GetUVal().GetValues() {
+			size += valueSize(v)
+		}
+	}
+	return size
+}
+
+// Drop the rows beyond the budget to release them, returns the dropped rows count
+func limitResultMemory(resp *graph.ExecutionResponse) int {
+	if maxResultMemory <= 0 {
+		return 0
+	}
+	used := int64(0)
+	dropped := 0
+	for _, table := range resp.GetData() {
+		rows := table.GetRows()
+		for i, row := range rows {
+			if used >= 0 {
+				for _, col := range row.GetColumns() {
+					used += valueSize(col)
+				}
+				if used <= maxResultMemory {
+					continue
+				}
+				used = -1
+			}
+			// Unreferenced from the backing array for the garbage collection
+			rows[i] = nil
+			dropped++
+		}
+		n := 0
+		for n < len(rows) && rows[n] != nil {
+			n++
+		}
+		table.Rows = rows[:n]
+	}
+	return dropped
+}
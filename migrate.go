@@ -0,0 +1,81 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// nebula-console migrate [flags] --dir <directory>
+// The scripts are applied in the order of the names like 001_schema.ngql, the applied statements are
+// recorded in the ledger, so the new scripts are applied only and the failed one is resumed when rerun
+func migrateMain(args []string) int {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	setUsage(fs, subcommands["migrate"].usage)
+	connFlags := addConnectionFlags(fs)
+	dir := fs.String("dir", "", "The directory of the *.ngql scripts")
+	ledgerFile := fs.String("ledger", "", "The ledger of the applied statements, default <dir>/.nebula_migrations")
+	fs.Parse(args)
+	if *dir == "" {
+		fs.Usage()
+		return exitUsageError
+	}
+	if *ledgerFile == "" {
+		*ledgerFile = filepath.Join(*dir, ".nebula_migrations")
+	}
+	scripts, err := filepath.Glob(filepath.Join(*dir, "*.ngql"))
+	if err != nil {
+		exitWith(exitFileError, "List the scripts of %s failed, %s", *dir, err.Error())
+	}
+	sort.Strings(scripts)
+	if len(scripts) == 0 {
+		exitWith(exitFileError, "No *.ngql scripts in %s", *dir)
+	}
+
+	conn, err = connFlags.Connection()
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "%s", err.Error())
+	}
+	client, err := newSession(conn)
+	if err != nil {
+		exitWith(errorExitCode(err, exitConnectError), "Fail to connect server, address: %s, username: %s, %s", conn.Address, conn.Username, err.Error())
+	}
+	sessions[defaultSession] = client
+	defer closeSessions()
+	defer client.Disconnect()
+	if stmtLedger, err = openLedger(*ledgerFile); err != nil {
+		exitWith(exitFileError, "Open ledger %s failed, %s", *ledgerFile, err.Error())
+	}
+	defer closeLedger()
+
+	for _, script := range scripts {
+		fmt.Printf("Migrating %s", script)
+		fmt.Println()
+		fd, err := os.Open(script)
+		if err != nil {
+			exitWith(exitFileError, "Open file %s failed, %s", script, err.Error())
+		}
+		stmtLedger.beginScript(script)
+		exit := loop(client, NewnCli(fd))
+		fd.Close()
+		if exit != nil || exitCode != 0 {
+			fmt.Printf("[ERROR] Migration %s failed, rerun to resume after fixing it", script)
+			fmt.Println()
+			if exitCode != 0 {
+				return exitCode
+			}
+			return exitStatementError
+		}
+	}
+	fmt.Printf("Migrated %d scripts.", len(scripts))
+	fmt.Println()
+	return 0
+}
@@ -0,0 +1,93 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The JSON value for jq, the numbers, booleans, nulls, lists and maps are typed,
+// the others like the vertices are the strings rendered as the table
+func ndjsonValue(value *common.Value, opts exportOptions) interface{} {
+	switch {
+	case value.IsSetNVal():
+		return nil
+	case value.IsSetBVal():
+		return value.GetBVal()
+	case value.IsSetIVal():
+		return value.GetIVal()
+	case value.IsSetFVal():
+		// Not representable in JSON
+		if f := value.GetFVal(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			return f
+		}
+	case value.IsSetSVal():
+		return opts.value(value)
+	case value.IsSetLVal():
+		items := make([]interface{}, 0, len(value.GetLVal().GetValues()))
+		for _, v := range value.GetLVal().GetValues() {
+			items = append(items, ndjsonValue(v, opts))
+		}
+		return items
+	case value.IsSetUVal():
+		items := make([]interface{}, 0, len(value.GetUVal().GetValues()))
+		for _, v := range value.GetUVal().GetValues() {
+			items = append(items, ndjsonValue(v, opts))
+		}
+		return items
+	case value.IsSetMVal():
+		kvs := make(map[string]interface{}, len(value.GetMVal().GetKvs()))
+		for k, v := range value.GetMVal().GetKvs() {
+			kvs[k] = ndjsonValue(v, opts)
+		}
+		return kvs
+	}
+	return val2String(value, 256)
+}
+
+// Print one JSON object per row in the column order as the row formatted, flushed per rows for the pipes
+func (t Table) PrintNDJSON(table *graph.DataSet) {
+	out.buffer()
+	defer out.unbuffer()
+	opts := exportOptions{binary: binaryStrings}
+	header := columnNames(table)
+	keys := make([][]byte, len(header))
+	for i, name := range header {
+		keys[i], _ = json.Marshal(name)
+	}
+	secret := secretColumns(header)
+	line := []byte{}
+	for i, row := range table.GetRows() {
+		line = append(line[:0], '{')
+		for j, col := range row.GetColumns() {
+			var value interface{} = secretMask
+			if secret == nil || !secret[j] {
+				value = ndjsonValue(col, opts)
+			}
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				encoded, _ = json.Marshal(fmt.Sprint(value))
+			}
+			if j > 0 {
+				line = append(line, ',')
+			}
+			line = append(line, keys[j]...)
+			line = append(line, ':')
+			line = append(line, encoded...)
+		}
+		line = append(line, '}', '\n')
+		out.Write(line)
+		if (i+1)%flushRows == 0 {
+			out.Flush()
+		}
+	}
+}
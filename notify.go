@@ -0,0 +1,60 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// The failure notification sent to the webhook
+type notification struct {
+	Source    string `json:"source"` // batch or schedule
+	Statement string `json:"statement"`
+	ErrorCode string `json:"error_code"`
+	Error     string `json:"error"`
+	Host      string `json:"host"`   // the host running the console
+	Server    string `json:"server"` // the graphd address
+	Time      string `json:"time"`
+}
+
+var webhookClient = &http.Client{Timeout: 5 * time.Second}
+
+// Post the failure to the configured webhook, the notification failure is only reported
+func notify(source string, stmt string, code string, msg string) {
+	if conf.Webhook.URL == "" {
+		return
+	}
+	host, _ := os.Hostname()
+	n := notification{source, redactStatement(stmt), code, msg, host, conn.Address, time.Now().Format(time.RFC3339)}
+	var payload interface{} = n
+	if conf.Webhook.Type == "slack" {
+		payload = map[string]string{
+			"text": fmt.Sprintf("nebula-console %s failed on %s (server %s)\n```%s```\n%s: %s",
+				n.Source, n.Host, n.Server, n.Statement, n.ErrorCode, n.Error),
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return
+	}
+	resp, err := webhookClient.Post(conf.Webhook.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[WARNING] Notify webhook failed, %s", err.Error())
+		fmt.Fprintln(os.Stderr)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		fmt.Fprintf(os.Stderr, "[WARNING] Notify webhook failed, %s", resp.Status)
+		fmt.Fprintln(os.Stderr)
+	}
+}
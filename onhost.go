@@ -0,0 +1,95 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The graphd endpoints by `--address graphd-1,graphd-2`, the console connects the first one
+var graphEndpoints []string
+
+// The sessions by the endpoint opened by `:on-host`
+var hostSessions = map[string]*Session{}
+
+// The endpoint by the address, or by the host without the port, e.g. `graphd-2` of `graphd-2:3699`
+func lookupEndpoint(host string) (string, error) {
+	matched := []string{}
+	for _, endpoint := range graphEndpoints {
+		if endpoint == host {
+			return endpoint, nil
+		}
+		// The IPv6 host is bracketed or not, e.g. `[::1]` or `::1` of `[::1]:3699`
+		if h, _, err := net.SplitHostPort(endpoint); err == nil && (h == host || "["+h+"]" == host) {
+			matched = append(matched, endpoint)
+		}
+	}
+	switch len(matched) {
+	case 0:
+		return "", fmt.Errorf("Unknown host `%s', expect one of %s", host, strings.Join(graphEndpoints, ", "))
+	case 1:
+		return matched[0], nil
+	}
+	return "", fmt.Errorf("Ambiguous host `%s', candidates: %s", host, strings.Join(matched, ", "))
+}
+
+// :on-host <host> <statement>, e.g. `:on-host graphd-2 SHOW CONFIGS`
+// Execute the statement by the session of the graphd in the current space, labeled by the host,
+// list the endpoints without arguments
+func onHostCmd(client *Session, c Cli, args string) error {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		for _, endpoint := range graphEndpoints {
+			mark := ""
+			if endpoint == client.conn.Address {
+				mark = " (connected)"
+			}
+			fmt.Printf("%s%s", endpoint, mark)
+			fmt.Println()
+		}
+		return nil
+	}
+	fields := strings.SplitN(args, " ", 2)
+	if len(fields) != 2 || strings.TrimSpace(fields[1]) == "" {
+		return fmt.Errorf("Usage: :on-host <host> <statement>")
+	}
+	endpoint, err := lookupEndpoint(fields[0])
+	if err != nil {
+		return err
+	}
+	if err = confirmStatement(c, fields[1]); err != nil {
+		return err
+	}
+	session := client
+	if endpoint != client.conn.Address {
+		var ok bool
+		if session, ok = hostSessions[endpoint]; !ok {
+			if session, err = newSession(Connection{endpoint, client.conn.Username, client.conn.Password}); err != nil {
+				return fmt.Errorf("Connect %s failed, %s", endpoint, err.Error())
+			}
+			hostSessions[endpoint] = session
+		}
+		if client.space != "" && session.space != client.space {
+			resp, err := session.Execute("USE " + quoteName(client.space))
+			if err != nil {
+				return fmt.Errorf("USE %s on %s failed, %s", client.space, endpoint, err.Error())
+			}
+			if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+				return fmt.Errorf("USE %s on %s failed, %s", client.space, endpoint, errorString(resp))
+			}
+		}
+	}
+	fmt.Fprintf(out, "[HOST %s]", endpoint)
+	fmt.Fprintln(out)
+	// Printed, journaled and tracked like the typed statement, only executed by the pinned session
+	_, err = runStatement(session, c, fields[1])
+	return err
+}
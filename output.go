@@ -0,0 +1,155 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// The results writer, the screen may be redirected to the pager,
+// and all is duplicated to the tee file if any
+type output struct {
+	screen io.Writer
+	tee    *os.File
+	// Buffered while printing the tables to not write by each cell
+	buffered *bufio.Writer
+}
+
+// Flush the buffer per the rows of the long table, so it's still shown progressively
+const flushRows = 1000
+
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
+
+func (o *output) Write(p []byte) (int, error) {
+	if o.buffered != nil {
+		return o.buffered.Write(p)
+	}
+	return o.write(p)
+}
+
+func (o *output) write(p []byte) (int, error) {
+	if o.tee != nil {
+		o.tee.Write(stripColors(p))
+	}
+	return o.screen.Write(p)
+}
+
+// Buffer the writes until unbuffer, the screen is resolved when flushed, e.g. the pager
+func (o *output) buffer() {
+	if o.buffered == nil {
+		o.buffered = bufio.NewWriterSize(writerFunc(o.write), 64*1024)
+	}
+}
+
+// Write the buffered out but keep buffering
+func (o *output) Flush() error {
+	if o.buffered == nil {
+		return nil
+	}
+	return o.buffered.Flush()
+}
+
+func (o *output) unbuffer() {
+	o.Flush()
+	o.buffered = nil
+}
+
+// All the results are written to out
+var out = &output{screen: os.Stdout}
+
+// The terminal is held by the console while executing and printing the statements, and released at the prompt
+// and while reading the scripts, so the messages of the background, e.g. the schedules and the async jobs,
+// never interleave with the prompt or the result tables
+var (
+	terminalMutex sync.Mutex
+	terminalHeld  = false
+	// The prompt redrawn after the background messages, set while reading interactively
+	promptWriter io.Writer
+)
+
+// Called by the console goroutine only
+func holdTerminal() {
+	if !terminalHeld {
+		terminalMutex.Lock()
+		terminalHeld = true
+	}
+}
+
+func releaseTerminal() {
+	if terminalHeld {
+		terminalHeld = false
+		terminalMutex.Unlock()
+	}
+}
+
+// Run the blocking f without holding the terminal, e.g. reading the script or waiting the background goroutine
+func withoutTerminal(f func()) {
+	held := terminalHeld
+	releaseTerminal()
+	f()
+	if held {
+		holdTerminal()
+	}
+}
+
+// Print the line of the background once the terminal released, above the prompt if reading
+func printBackground(format string, args ...interface{}) {
+	terminalMutex.Lock()
+	defer terminalMutex.Unlock()
+	w := io.Writer(out)
+	if promptWriter != nil {
+		w = promptWriter
+	}
+	fmt.Fprintf(w, format, args...)
+	fmt.Fprintln(w)
+}
+
+// Write the executed statement to the tee file for the transcript
+func teeStatement(stmt string) {
+	if out.tee != nil {
+		fmt.Fprintln(out.tee, strings.TrimSpace(redactStatement(stmt)))
+	}
+}
+
+func closeTee() {
+	if out.tee != nil {
+		out.tee.Close()
+		out.tee = nil
+	}
+}
+
+// :tee <file>
+func teeCmd(client *Session, c Cli, args string) error {
+	file := strings.TrimSpace(args)
+	if file == "" {
+		return fmt.Errorf("Usage: :tee <file>")
+	}
+	fd, err := os.OpenFile(file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	closeTee()
+	out.tee = fd
+	fmt.Printf("Logging to file `%s'.", file)
+	fmt.Println()
+	return nil
+}
+
+// :notee
+func noteeCmd(client *Session, c Cli, args string) error {
+	closeTee()
+	return nil
+}
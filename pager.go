@@ -0,0 +1,119 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+	readline "github.com/shylock-hg/readline"
+)
+
+// Enabled in interactive mode by default
+var pagerEnabled = false
+
+const defaultPager = "less -RS"
+
+// :pager on|off
+func pagerCmd(client *Session, c Cli, args string) error {
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		fmt.Printf("Pager is %s.", onOff(pagerEnabled))
+		fmt.Println()
+	case "on":
+		pagerEnabled = true
+	case "off":
+		pagerEnabled = false
+	default:
+		return fmt.Errorf("Usage: :pager on|off")
+	}
+	return nil
+}
+
+func onOff(b bool) string {
+	if b {
+		return "on"
+	}
+	return "off"
+}
+
+// The lines count of the response printed
+func respLines(resp *graph.ExecutionResponse, format string) int {
+	lines := 2 // time spent and the timestamp
+	for _, table := range resp.GetData() {
+		table, _ = limitRows(table)
+		if format == formatVertical {
+			lines += len(table.GetRows())*(len(table.GetColumnNames())+1) + 1
+		} else {
+			lines += len(table.GetRows())*2 + 4
+			if pageRows > 0 {
+				lines += len(table.GetRows()) / pageRows * 3
+			}
+		}
+	}
+	return lines
+}
+
+func pagerCommand() string {
+	if pager := os.Getenv("PAGER"); pager != "" {
+		return pager
+	}
+	return defaultPager
+}
+
+// Run the shell command attached to the terminal, e.g. the pager or editor,
+// which handles Ctrl+C itself
+func runTerminalCommand(command string, stdin io.Reader) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdin = stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	return cmd.Run()
+}
+
+// Redirect the output to $PAGER if the lines exceed the terminal height,
+// returns the function to wait the pager exit, or nil if not paged
+func startPager(lines int) func() {
+	if !pagerEnabled || !readline.IsTerminal(int(os.Stdout.Fd())) {
+		return nil
+	}
+	height := terminalHeight(os.Stdout.Fd())
+	if height == 0 || lines < height {
+		return nil
+	}
+	pager := pagerCommand()
+	cmd := exec.Command("sh", "-c", pager)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil
+	}
+	if err = cmd.Start(); err != nil {
+		fmt.Printf("[WARNING] Start pager `%s' failed, %s", pager, err.Error())
+		fmt.Println()
+		return nil
+	}
+	// The pager handles Ctrl+C itself
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	out.screen = stdin
+	return func() {
+		stdin.Close()
+		cmd.Wait()
+		out.screen = os.Stdout
+		signal.Stop(interrupt)
+	}
+}
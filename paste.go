@@ -0,0 +1,107 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// The commands printing the clipboard content, tried in order
+func clipboardCommands() [][]string {
+	switch runtime.GOOS {
+	case "darwin":
+		return [][]string{{"pbpaste"}}
+	case "windows":
+		return [][]string{{"powershell", "-NoProfile", "-Command", "Get-Clipboard"}}
+	}
+	return [][]string{
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-out", "-selection", "clipboard"},
+		{"xsel", "--output", "--clipboard"},
+	}
+}
+
+func readClipboard() (string, error) {
+	for _, command := range clipboardCommands() {
+		if _, err := exec.LookPath(command[0]); err != nil {
+			continue
+		}
+		content, err := exec.Command(command[0], command[1:]...).Output()
+		if err != nil {
+			return "", fmt.Errorf("Read clipboard by `%s' failed, %s", command[0], err.Error())
+		}
+		return strings.Replace(string(content), "\r\n", "\n", -1), nil
+	}
+	return "", fmt.Errorf("No clipboard command found, paste the rows by `:paste-insert <tag> stdin'")
+}
+
+// Read the pasted lines until the blank line or EOF
+func readPasted(c Cli) (string, error) {
+	fmt.Println("Paste the rows, end with a blank line.")
+	lines := []string{}
+	for {
+		line, err, exit := c.Ask("paste> ")
+		if err != nil {
+			return "", err
+		}
+		if exit || strings.TrimSpace(line) == "" {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// :paste-insert <tag> [stdin]
+// Insert the tab or comma separated rows from the clipboard, the header is optional,
+// without it the fields are the vid and then the properties in the schema order
+func pasteInsertCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 || (len(fields) == 2 && fields[1] != "stdin") {
+		return fmt.Errorf("Usage: :paste-insert <tag> [stdin]")
+	}
+	if client.space == "" {
+		return fmt.Errorf("No space used, USE <space> first")
+	}
+	var content string
+	var err error
+	if len(fields) == 2 {
+		content, err = readPasted(c)
+	} else {
+		content, err = readClipboard()
+	}
+	if err != nil {
+		return err
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return fmt.Errorf("No rows pasted")
+	}
+
+	im := &importer{client: client, space: client.space, tag: fields[0], vidColumn: "vid",
+		batchSize: 100, comma: ',', detectHeader: true}
+	if firstLine := strings.SplitN(content, "\n", 2)[0]; strings.Contains(firstLine, "\t") {
+		im.comma = '\t'
+	}
+	im.intVid = isIntVidSpace(client)
+	if err = im.describe(); err != nil {
+		return err
+	}
+	imported, failed, err := im.run(strings.NewReader(content))
+	fmt.Printf("Inserted %d vertices, failed %d.", imported, failed)
+	fmt.Println()
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d vertices failed", failed)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The statement evaluated by graphd only, without touching storage
+const pingStatement = "YIELD 1"
+
+// One round trip to graphd
+func ping(client *Session) (time.Duration, error) {
+	start := time.Now()
+	resp, err := client.Execute(pingStatement)
+	duration := time.Since(start)
+	if err != nil {
+		return duration, err
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return duration, fmt.Errorf("%s", errorString(resp))
+	}
+	return duration, nil
+}
+
+// :ping [count]
+func pingCmd(client *Session, c Cli, args string) error {
+	count := 4
+	if s := strings.TrimSpace(args); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return fmt.Errorf("Usage: :ping [count]")
+		}
+		count = n
+	}
+	var min, max, total time.Duration
+	for i := 0; i < count; i++ {
+		d, err := ping(client)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reply from %s: seq=%d time=%.3f ms", client.conn.Address, i+1, float64(d)/float64(time.Millisecond))
+		fmt.Println()
+		if i == 0 || d < min {
+			min = d
+		}
+		if d > max {
+			max = d
+		}
+		total += d
+	}
+	fmt.Printf("%d round trips, min/avg/max = %.3f/%.3f/%.3f ms", count,
+		float64(min)/float64(time.Millisecond),
+		float64(total)/float64(count)/float64(time.Millisecond),
+		float64(max)/float64(time.Millisecond))
+	fmt.Println()
+	return nil
+}
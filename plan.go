@@ -0,0 +1,197 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The operator of the execution plan returned by EXPLAIN/PROFILE
+type planNode struct {
+	id           string
+	name         string
+	dependencies []string
+	profiling    string
+	info         string
+}
+
+// The columns of the plan in the row format
+var planColumns = []string{"id", "name", "dependencies"}
+
+func planColumnIndex(header []string, name string) int {
+	for i, h := range header {
+		if strings.EqualFold(h, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// The statement returns the plan, e.g. `EXPLAIN GO ...` or `USE s; PROFILE GO ...`
+var planStatement = regexp.MustCompile(`(?i)(^|;)\s*(EXPLAIN|PROFILE)\s`)
+
+func returnsPlan(stmt string) bool {
+	return planStatement.MatchString(stmt)
+}
+
+// The plan among the results of the statement returnsPlan, recognized by the columns,
+// the query returning the same columns isn't a plan so check the statement first
+func isPlanTable(table *graph.DataSet) bool {
+	header := columnNames(table)
+	for _, name := range planColumns {
+		if planColumnIndex(header, name) < 0 {
+			return false
+		}
+	}
+	return len(table.GetRows()) > 0
+}
+
+func planNodes(table *graph.DataSet) []*planNode {
+	header := columnNames(table)
+	column := func(row *graph.Row, name string) string {
+		i := planColumnIndex(header, name)
+		if i < 0 || i >= len(row.GetColumns()) {
+			return ""
+		}
+		return exportValue(row.GetColumns()[i])
+	}
+	nodes := make([]*planNode, 0, len(table.GetRows()))
+	for _, row := range table.GetRows() {
+		deps := strings.FieldsFunc(column(row, "dependencies"), func(r rune) bool {
+			return r == ',' || r == ' ' || r == '[' || r == ']'
+		})
+		nodes = append(nodes, &planNode{column(row, "id"), column(row, "name"), deps,
+			column(row, "profiling data"), column(row, "operator info")})
+	}
+	return nodes
+}
+
+var (
+	profilingRows = regexp.MustCompile(`rows:\s*(\d+)`)
+	profilingTime = regexp.MustCompile(`execTime:\s*([0-9.]+\s*[a-zµ]*)`)
+)
+
+// The rows and the execution time of the operator profiled, or all the stats reported if showing the resource usage,
+// empty for EXPLAIN
+func (n *planNode) stats() string {
+	stats := []string{}
+	if showResourceUsage {
+		for _, pair := range profilingStats(n.profiling) {
+			if pair[0] != "ver" {
+				stats = append(stats, pair[0]+": "+pair[1])
+			}
+		}
+	} else {
+		if m := profilingRows.FindStringSubmatch(n.profiling); m != nil {
+			stats = append(stats, "rows: "+m[1])
+		}
+		if m := profilingTime.FindStringSubmatch(n.profiling); m != nil {
+			stats = append(stats, "time: "+strings.TrimSpace(m[1]))
+		}
+	}
+	if len(stats) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(stats, ", ") + ")"
+}
+
+// The roots are the operators not depended by the others, i.e. the output ones
+func planRoots(nodes []*planNode) []*planNode {
+	depended := map[string]bool{}
+	for _, n := range nodes {
+		for _, dep := range n.dependencies {
+			depended[dep] = true
+		}
+	}
+	roots := []*planNode{}
+	for _, n := range nodes {
+		if !depended[n.id] {
+			roots = append(roots, n)
+		}
+	}
+	// The larger id is closer to the output
+	sort.SliceStable(roots, func(i, j int) bool {
+		a, errA := strconv.Atoi(roots[i].id)
+		b, errB := strconv.Atoi(roots[j].id)
+		return errA == nil && errB == nil && a > b
+	})
+	return roots
+}
+
+func planInfoColor() string {
+	if !colorEnabled() {
+		return ""
+	}
+	return colorGray
+}
+
+// Print the operator and its dependencies as the tree, the shared operator of the DAG is expanded once
+// and referenced by id later
+func printPlanNode(n *planNode, byID map[string]*planNode, prefix string, branch string, printed map[string]bool) {
+	fmt.Fprintf(out, "%s%s%s [%s]%s", prefix, branch, n.name, n.id, n.stats())
+	if printed[n.id] {
+		fmt.Fprint(out, " ↑")
+		fmt.Fprintln(out)
+		return
+	}
+	fmt.Fprintln(out)
+	printed[n.id] = true
+
+	childPrefix := prefix
+	switch branch {
+	case "├─ ":
+		childPrefix += "│  "
+	case "└─ ":
+		childPrefix += "   "
+	}
+	if n.info != "" {
+		bar := "   "
+		if len(n.dependencies) > 0 {
+			bar = "│  "
+		}
+		for _, line := range strings.Split(strings.TrimSpace(n.info), "\n") {
+			fmt.Fprint(out, colorize(fmt.Sprintf("%s%s%s", childPrefix, bar, line), planInfoColor()))
+			fmt.Fprintln(out)
+		}
+	}
+	for i, dep := range n.dependencies {
+		child, ok := byID[dep]
+		if !ok {
+			child = &planNode{id: dep, name: "?"}
+		}
+		childBranch := "├─ "
+		if i == len(n.dependencies)-1 {
+			childBranch = "└─ "
+		}
+		printPlanNode(child, byID, childPrefix, childBranch, printed)
+	}
+}
+
+// Print the plan of EXPLAIN/PROFILE as the tree from the output operator to the start one
+func (t Table) PrintPlan(table *graph.DataSet) {
+	nodes := planNodes(table)
+	byID := make(map[string]*planNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.id] = n
+	}
+	printed := map[string]bool{}
+	roots := planRoots(nodes)
+	if len(roots) == 0 && len(nodes) > 0 {
+		// All in a loop, start from the first one
+		roots = nodes[:1]
+	}
+	for _, root := range roots {
+		printPlanNode(root, byID, "", "", printed)
+	}
+	fmt.Fprintln(out)
+}
@@ -0,0 +1,177 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The plan shape of each run by `:plans`, keyed by the statement with the whitespace collapsed
+type planRun struct {
+	at    time.Time
+	shape string
+}
+
+var planRuns = map[string][]planRun{}
+
+// The statement is explained again, e.g. `:plans PROFILE GO ...` is `EXPLAIN GO ...`
+var explainPrefix = regexp.MustCompile(`(?i)^\s*(EXPLAIN|PROFILE)\s+`)
+
+// The operators and their dependencies from the output one, without the ids, the variables
+// and the profiling data, which differ by the runs of the same plan
+func planShape(table *graph.DataSet) string {
+	nodes := planNodes(table)
+	byID := make(map[string]*planNode, len(nodes))
+	for _, n := range nodes {
+		byID[n.id] = n
+	}
+	// The shared operator is numbered by the visiting order
+	visited := map[string]int{}
+	var shape func(n *planNode, sb *strings.Builder)
+	shape = func(n *planNode, sb *strings.Builder) {
+		if i, ok := visited[n.id]; ok {
+			fmt.Fprintf(sb, "^%d", i)
+			return
+		}
+		visited[n.id] = len(visited)
+		sb.WriteString(n.name)
+		if len(n.dependencies) == 0 {
+			return
+		}
+		sb.WriteByte('(')
+		for i, dep := range n.dependencies {
+			if i > 0 {
+				sb.WriteByte(',')
+			}
+			if child, ok := byID[dep]; ok {
+				shape(child, sb)
+			} else {
+				sb.WriteByte('?')
+			}
+		}
+		sb.WriteByte(')')
+	}
+	var sb strings.Builder
+	for i, root := range planRoots(nodes) {
+		if i > 0 {
+			sb.WriteByte(';')
+		}
+		shape(root, &sb)
+	}
+	return sb.String()
+}
+
+func shapeHash(shape string) string {
+	sum := sha256.Sum256([]byte(shape))
+	return hex.EncodeToString(sum[:4])
+}
+
+// :plans [--runs <n>] <statement>
+// Explain the statement n times and compare the plan shapes with the previous runs of the session,
+// the new shape is printed as the tree, list the statements tracked without arguments
+func plansCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		stmts := make([]string, 0, len(planRuns))
+		for stmt := range planRuns {
+			stmts = append(stmts, stmt)
+		}
+		sort.Strings(stmts)
+		for _, stmt := range stmts {
+			printPlanRuns(stmt)
+		}
+		return nil
+	}
+	runs := 1
+	if fields[0] == "--runs" {
+		n := 0
+		if len(fields) > 1 {
+			n, _ = strconv.Atoi(fields[1])
+		}
+		if n <= 0 {
+			return fmt.Errorf("Usage: :plans [--runs <n>] <statement>")
+		}
+		runs, fields = n, fields[2:]
+	}
+	stmt := strings.TrimSuffix(explainPrefix.ReplaceAllString(strings.Join(fields, " "), ""), ";")
+	if strings.TrimSpace(stmt) == "" {
+		return fmt.Errorf("Usage: :plans [--runs <n>] <statement>")
+	}
+	stmt, err := substituteVariables(stmt)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < runs; i++ {
+		resp, err := client.Execute("EXPLAIN " + stmt)
+		if err != nil {
+			return err
+		}
+		if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			return fmt.Errorf("EXPLAIN failed, %s", errorString(resp))
+		}
+		var plan *graph.DataSet
+		for _, table := range resp.GetData() {
+			if isPlanTable(table) {
+				plan = table
+			}
+		}
+		if plan == nil {
+			return fmt.Errorf("No plan returned by EXPLAIN")
+		}
+		shape := planShape(plan)
+		seen := false
+		for _, run := range planRuns[stmt] {
+			seen = seen || run.shape == shape
+		}
+		planRuns[stmt] = append(planRuns[stmt], planRun{time.Now(), shape})
+		n := len(planRuns[stmt])
+		if seen {
+			fmt.Fprintf(out, "#%d plan %s", n, shapeHash(shape))
+			fmt.Fprintln(out)
+			continue
+		}
+		if n == 1 {
+			fmt.Fprintf(out, "#%d plan %s", n, shapeHash(shape))
+		} else {
+			fmt.Fprintf(out, "#%d plan %s changed", n, shapeHash(shape))
+		}
+		fmt.Fprintln(out)
+		t.PrintPlan(plan)
+	}
+	printPlanRuns(stmt)
+	return nil
+}
+
+// The distinct shapes of the statement in the first seen order, with the runs and the last seen time
+func printPlanRuns(stmt string) {
+	runs := planRuns[stmt]
+	order := []string{}
+	counts := map[string]int{}
+	last := map[string]time.Time{}
+	for _, run := range runs {
+		if counts[run.shape] == 0 {
+			order = append(order, run.shape)
+		}
+		counts[run.shape]++
+		last[run.shape] = run.at
+	}
+	fmt.Fprintf(out, "`%s': %d runs, %d plans", stmt, len(runs), len(order))
+	fmt.Fprintln(out)
+	for _, shape := range order {
+		fmt.Fprintf(out, "  %s %d runs, last at %s", shapeHash(shape), counts[shape], last[shape].Format("15:04:05"))
+		fmt.Fprintln(out)
+	}
+}
@@ -0,0 +1,110 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Quote the bare vertex ids in FETCH/GO automatically for string vid space
+var autoQuote = true
+
+// Whether the vid type of the space is integer, cached by space
+var intVidSpaces = map[string]bool{}
+
+func isIntVidSpace(client *Session) bool {
+	if client.space == "" {
+		return false
+	}
+	if isInt, ok := intVidSpaces[client.space]; ok {
+		return isInt
+	}
+	desc, err := describeSpace(client, client.space)
+	if err != nil {
+		return false
+	}
+	isInt := strings.Contains(strings.ToUpper(desc["vid_type"]), "INT")
+	intVidSpaces[client.space] = isInt
+	return isInt
+}
+
+// Quote and escape the string as nGQL literal
+func quoteVid(vid string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+	return `"` + r.Replace(vid) + `"`
+}
+
+// Quote the name like the space by backticks, e.g. the keywords or the names with `-`
+func quoteName(name string) string {
+	return "`" + strings.Replace(name, "`", "\\`", -1) + "`"
+}
+
+func isQuoted(s string) bool {
+	return len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0]
+}
+
+var (
+	fetchVidsPattern = regexp.MustCompile(`(?is)^(\s*FETCH\s+PROP\s+ON\s+[\w*]+\s+)([^\s,;\\]+(?:\s*,\s*[^\s,;\\]+)*?)(\s+YIELD\b.*|\s*[;]?\s*(?:\\G)?\s*)$`)
+	goVidsPattern    = regexp.MustCompile(`(?is)^(\s*GO\s+(?:\d+\s+STEPS\s+)?FROM\s+)([^\s,;\\]+(?:\s*,\s*[^\s,;\\]+)*?)(\s+OVER\b.*)$`)
+)
+
+// Quote the bare vids of FETCH/GO, returns the rewritten statement and whether changed
+func quoteStatementVids(stmt string) (string, bool) {
+	for _, pattern := range []*regexp.Regexp{fetchVidsPattern, goVidsPattern} {
+		m := pattern.FindStringSubmatch(stmt)
+		if m == nil {
+			continue
+		}
+		changed := false
+		vids := strings.Split(m[2], ",")
+		for i, vid := range vids {
+			vid = strings.TrimSpace(vid)
+			// Quoted, variable reference, function call or expression
+			if isQuoted(vid) || strings.ContainsAny(vid, "$()+-*/\"'") {
+				vids[i] = vid
+				continue
+			}
+			vids[i] = quoteVid(vid)
+			changed = true
+		}
+		return m[1] + strings.Join(vids, ", ") + m[3], changed
+	}
+	return stmt, false
+}
+
+func autoQuoteVids(client *Session, stmt string) string {
+	if !autoQuote {
+		return stmt
+	}
+	quoted, changed := quoteStatementVids(stmt)
+	if !changed || isIntVidSpace(client) {
+		return stmt
+	}
+	fmt.Printf("[NOTICE] Quoted vertex ids: %s", strings.TrimSpace(quoted))
+	fmt.Println()
+	return quoted
+}
+
+// :quote <raw id>
+func quoteCmd(client *Session, c Cli, args string) error {
+	raw := strings.TrimSpace(args)
+	if raw == "" {
+		return fmt.Errorf("Usage: :quote <raw id>")
+	}
+	if isIntVidSpace(client) {
+		if _, err := strconv.ParseInt(raw, 10, 64); err != nil {
+			return fmt.Errorf("The vid type of space %s is integer, `%s' is not", client.space, raw)
+		}
+		fmt.Println(raw)
+		return nil
+	}
+	fmt.Println(quoteVid(raw))
+	return nil
+}
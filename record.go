@@ -0,0 +1,195 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shylock-hg/readline"
+)
+
+// Record the interactive session in the asciinema v2 format, i.e. the header then events `[time, "o"|"i", data]`
+type recorder struct {
+	mutex    sync.Mutex
+	file     *os.File
+	start    time.Time
+	terminal *os.File
+	pipe     *os.File
+	done     chan struct{}
+	// The events of the line being typed, held until read to redact the secret statement
+	holding bool
+	held    [][]byte
+}
+
+// The recording by `--record-session`, nil if not recording
+var sessionRecorder *recorder
+
+type castHeader struct {
+	Version   int               `json:"version"`
+	Width     int               `json:"width"`
+	Height    int               `json:"height"`
+	Timestamp int64             `json:"timestamp"`
+	Env       map[string]string `json:"env"`
+}
+
+// Split the bytes of the incomplete rune at the end, which are written with the next event
+func splitIncompleteRune(b []byte) ([]byte, []byte) {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				return b[:i], b[i:]
+			}
+			break
+		}
+	}
+	return b, nil
+}
+
+func (r *recorder) event(kind string, data []byte) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	event, _ := json.Marshal([]interface{}{time.Since(r.start).Seconds(), kind, string(data)})
+	if r.holding {
+		r.held = append(r.held, event)
+		return
+	}
+	r.file.Write(append(event, '\n'))
+}
+
+// Hold the events while readline reads the line
+func (r *recorder) holdLine() {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.holding = true
+}
+
+// Write the held events of the line read, the keystrokes and the echo of the secret statement
+// are replaced by the redacted one after the prompt, i.e. the events before the first input
+func (r *recorder) releaseLine(line string, secret bool) {
+	if r == nil {
+		return
+	}
+	r.mutex.Lock()
+	held := r.held
+	r.holding, r.held = false, nil
+	r.mutex.Unlock()
+	if secret {
+		for i, event := range held {
+			var e []interface{}
+			if json.Unmarshal(event, &e) == nil && len(e) == 3 && e[1] == "i" {
+				held = held[:i]
+				break
+			}
+		}
+	}
+	r.mutex.Lock()
+	for _, event := range held {
+		r.file.Write(append(event, '\n'))
+	}
+	r.mutex.Unlock()
+	if secret {
+		r.event("o", []byte(redactLine(line)+"\r\n"))
+	}
+}
+
+// Capture the output by replacing os.Stdout with a pipe copied to the terminal
+func startRecording(file string) error {
+	// The typed statements are sensitive as the history
+	fd, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	width, height := readline.GetScreenWidth(), terminalHeight(os.Stdout.Fd())
+	if width <= 0 {
+		width = 80
+	}
+	if height == 0 {
+		height = 24
+	}
+	header, _ := json.Marshal(castHeader{2, width, height, time.Now().Unix(),
+		map[string]string{"SHELL": os.Getenv("SHELL"), "TERM": os.Getenv("TERM")}})
+	if _, err = fd.Write(append(header, '\n')); err != nil {
+		fd.Close()
+		return err
+	}
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		fd.Close()
+		return err
+	}
+	r := &recorder{file: fd, start: time.Now(), terminal: os.Stdout, pipe: writer, done: make(chan struct{})}
+	go func() {
+		defer close(r.done)
+		buf := make([]byte, 32*1024)
+		var rest []byte
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				r.terminal.Write(buf[:n])
+				var data []byte
+				data, rest = splitIncompleteRune(append(rest, buf[:n]...))
+				r.event("o", data)
+			}
+			if err != nil {
+				reader.Close()
+				return
+			}
+		}
+	}()
+	os.Stdout = writer
+	out.screen = writer
+	sessionRecorder = r
+	return nil
+}
+
+// Record the output of readline synchronously instead of by the pipe, so the line is held until read
+type recordedOutput struct {
+	*os.File
+}
+
+func (o recordedOutput) Write(p []byte) (int, error) {
+	n, err := o.File.Write(p)
+	if n > 0 && sessionRecorder != nil {
+		sessionRecorder.event("o", p[:n])
+	}
+	return n, err
+}
+
+// Record the input read by readline
+type recordedInput struct {
+	io.ReadCloser
+}
+
+func (in recordedInput) Read(p []byte) (int, error) {
+	n, err := in.ReadCloser.Read(p)
+	if n > 0 && sessionRecorder != nil {
+		sessionRecorder.event("i", p[:n])
+	}
+	return n, err
+}
+
+// Restore the stdout and flush the recording
+func closeRecording() {
+	r := sessionRecorder
+	if r == nil {
+		return
+	}
+	sessionRecorder = nil
+	os.Stdout = r.terminal
+	out.screen = r.terminal
+	r.pipe.Close()
+	<-r.done
+	r.file.Close()
+}
@@ -0,0 +1,108 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The outcome of one statement of the script run
+type reportEntry struct {
+	stmt    string
+	status  string
+	latency int32
+	wall    time.Duration
+}
+
+// The per-statement report of `-f`, nil if not enabled
+type runReport struct {
+	// Print to stdout if `-`, otherwise write as CSV
+	file    string
+	entries []reportEntry
+}
+
+var stmtReport *runReport
+
+func reportStatement(stmt string, status string, latency int32, wall time.Duration) {
+	if stmtReport != nil {
+		stmtReport.entries = append(stmtReport.entries, reportEntry{redactStatement(stmt), status, latency, wall})
+	}
+}
+
+// The succeeded or the symbolic error code
+func respStatus(resp *graph.ExecutionResponse) string {
+	if resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED {
+		return "succeeded"
+	}
+	return resp.GetErrorCode().String()
+}
+
+var reportHeader = []string{"#", "statement", "status", "latency (us)", "wall (us)"}
+
+func (r *runReport) print() {
+	table := &graph.DataSet{}
+	for _, name := range reportHeader {
+		table.ColumnNames = append(table.ColumnNames, []byte(name))
+	}
+	failed := 0
+	var latency int64
+	var wall time.Duration
+	for i, e := range r.entries {
+		if e.status != "succeeded" && e.status != "skipped" {
+			failed++
+		}
+		latency += int64(e.latency)
+		wall += e.wall
+		table.Rows = append(table.Rows, &graph.Row{Columns: []*common.Value{
+			int64Value(int64(i + 1)), {SVal: []byte(e.stmt)}, {SVal: []byte(e.status)},
+			int64Value(int64(e.latency)), int64Value(e.wall.Microseconds())}})
+	}
+	t.PrintTable(table)
+	fmt.Fprintf(out, "%d statements, %d failed, latency %d us, wall %d us in total", len(r.entries), failed, latency,
+		wall.Microseconds())
+	fmt.Fprintln(out)
+}
+
+func (r *runReport) write() error {
+	fd, err := os.Create(r.file)
+	if err != nil {
+		return err
+	}
+	w := csv.NewWriter(fd)
+	w.Write(reportHeader)
+	for i, e := range r.entries {
+		w.Write([]string{strconv.Itoa(i + 1), e.stmt, e.status, strconv.FormatInt(int64(e.latency), 10),
+			strconv.FormatInt(e.wall.Microseconds(), 10)})
+	}
+	w.Flush()
+	if err = w.Error(); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// Print or write the report at the end of the run
+func closeReport() {
+	if stmtReport == nil {
+		return
+	}
+	if stmtReport.file == "-" {
+		stmtReport.print()
+	} else if err := stmtReport.write(); err != nil {
+		fmt.Printf("[ERROR] Write the report %s failed, %s", stmtReport.file, err.Error())
+		fmt.Println()
+	}
+	stmtReport = nil
+}
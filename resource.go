@@ -0,0 +1,80 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Show the rows, the result memory and the stats reported by the server after the time spent,
+// changed by `:set show_resource_usage on`
+var showResourceUsage = false
+
+// The operators reading the storage, their rows are the rows scanned
+var scanOperators = []string{"GetNeighbors", "GetVertices", "GetEdges", "IndexScan", "TagIndexFullScan",
+	"EdgeIndexFullScan", "TagIndexPrefixScan", "EdgeIndexPrefixScan", "TagIndexRangeScan", "EdgeIndexRangeScan"}
+
+// The `key: value' pairs of the profiling data, e.g. `ver: 0, rows: 1, execTime: 23us, totalTime: 25us`
+var profilingPair = regexp.MustCompile(`([A-Za-z_]+):\s*([^,{}]+)`)
+
+func profilingStats(profiling string) [][2]string {
+	stats := [][2]string{}
+	for _, m := range profilingPair.FindAllStringSubmatch(profiling, -1) {
+		stats = append(stats, [2]string{m[1], strings.TrimSpace(m[2])})
+	}
+	return stats
+}
+
+// The rows scanned by the storage operators of the profiled plans, false if no such operator profiled
+func rowsScanned(resp *graph.ExecutionResponse, explained bool) (int64, bool) {
+	scanned, found := int64(0), false
+	for _, table := range resp.GetData() {
+		if !explained || !isPlanTable(table) {
+			continue
+		}
+		for _, n := range planNodes(table) {
+			if !contains(scanOperators, n.name) {
+				continue
+			}
+			if m := profilingRows.FindStringSubmatch(n.profiling); m != nil {
+				rows, _ := strconv.ParseInt(m[1], 10, 64)
+				scanned += rows
+				found = true
+			}
+		}
+	}
+	return scanned, found
+}
+
+// The usage appended to the time spent, e.g. `, 10 rows, 1.2KB, 300 rows scanned`
+func resourceUsage(resp *graph.ExecutionResponse, explained bool) string {
+	rows, size := 0, int64(0)
+	for _, table := range resp.GetData() {
+		if explained && isPlanTable(table) {
+			continue
+		}
+		rows += len(table.GetRows())
+		for _, row := range table.GetRows() {
+			for _, col := range row.GetColumns() {
+				size += valueSize(col)
+			}
+		}
+	}
+	usage := fmt.Sprintf(", %d rows, %s", rows, formatByteSize(size))
+	if scanned, ok := rowsScanned(resp, explained); ok {
+		usage += fmt.Sprintf(", %d rows scanned", scanned)
+	}
+	if comment := strings.TrimSpace(string(resp.GetComment())); comment != "" {
+		usage += ", " + comment
+	}
+	return usage
+}
@@ -0,0 +1,114 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The last succeeded response, for re-displaying, and whether its statement returnsPlan
+var (
+	lastResp      *graph.ExecutionResponse
+	lastExplained bool
+)
+
+func cacheResp(resp *graph.ExecutionResponse, explained bool) {
+	if resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED {
+		lastResp, lastExplained = resp, explained
+	}
+}
+
+// The first cell of the last result, empty if none
+func lastFirstValue() string {
+	if lastResp == nil {
+		return ""
+	}
+	for _, table := range lastResp.GetData() {
+		for _, row := range table.GetRows() {
+			if len(row.GetColumns()) > 0 {
+				return exactString(row.GetColumns()[0])
+			}
+		}
+	}
+	return ""
+}
+
+// :dedup
+// Collapse the duplicate rows of the last result with a count column, the most duplicated first
+func dedupCmd(client *Session, c Cli, args string) error {
+	if lastResp == nil {
+		return fmt.Errorf("No result")
+	}
+	for _, table := range lastResp.GetData() {
+		header := append([][]byte{}, table.GetColumnNames()...)
+		dedup := &graph.DataSet{ColumnNames: append(header, []byte("count"))}
+		index := map[string]int{}
+		counts := []int64{}
+		for _, row := range table.GetRows() {
+			key := rowKey(row)
+			if i, ok := index[key]; ok {
+				counts[i]++
+				continue
+			}
+			index[key] = len(dedup.Rows)
+			counts = append(counts, 1)
+			dedup.Rows = append(dedup.Rows, &graph.Row{Columns: append([]*common.Value{}, row.GetColumns()...)})
+		}
+		for i, row := range dedup.Rows {
+			count := counts[i]
+			row.Columns = append(row.Columns, &common.Value{IVal: &count})
+		}
+		sort.SliceStable(dedup.Rows, func(i, j int) bool {
+			return dedup.Rows[i].Columns[len(dedup.Rows[i].Columns)-1].GetIVal() >
+				dedup.Rows[j].Columns[len(dedup.Rows[j].Columns)-1].GetIVal()
+		})
+		t.PrintTable(dedup)
+	}
+	return nil
+}
+
+// :reflow
+// Print the last result again with the columns width of all rows, instead of the first page
+func reflowCmd(client *Session, c Cli, args string) error {
+	if lastResp == nil {
+		return fmt.Errorf("No result")
+	}
+	for _, table := range lastResp.GetData() {
+		t.printTable(table, 0)
+	}
+	return nil
+}
+
+// :show last [n], re-render the last result, or its nth table of the multiple ones
+func showCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 || len(fields) > 2 || fields[0] != "last" {
+		return fmt.Errorf("Usage: :show last [n]")
+	}
+	if lastResp == nil {
+		return fmt.Errorf("No result")
+	}
+	tables := lastResp.GetData()
+	if len(fields) == 1 {
+		printResp(lastResp, 0, outputFormat, lastExplained)
+		return nil
+	}
+	n, err := strconv.Atoi(fields[1])
+	if err != nil || n < 1 || n > len(tables) {
+		return fmt.Errorf("Expect the result 1 to %d, got `%s'", len(tables), fields[1])
+	}
+	fmt.Fprintf(out, "Result %d/%d", n, len(tables))
+	fmt.Fprintln(out)
+	printData(tables[n-1], outputFormat, lastExplained)
+	return nil
+}
@@ -0,0 +1,61 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"math/rand"
+	"sort"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The seed of the export sampling by default, the same rows are sampled for the same result
+const defaultSampleSeed = 1
+
+// Sample the rows in the original order, by the fraction or reservoir of the rows
+func sampleTable(table *graph.DataSet, opts exportOptions) *graph.DataSet {
+	random := rand.New(rand.NewSource(opts.sampleSeed))
+	sampled := &graph.DataSet{ColumnNames: table.GetColumnNames()}
+	rows := table.GetRows()
+	if opts.sampleRows > 0 {
+		if len(rows) <= opts.sampleRows {
+			sampled.Rows = rows
+			return sampled
+		}
+		reservoir := make([]int, opts.sampleRows)
+		for i := range reservoir {
+			reservoir[i] = i
+		}
+		for i := opts.sampleRows; i < len(rows); i++ {
+			if j := random.Intn(i + 1); j < opts.sampleRows {
+				reservoir[j] = i
+			}
+		}
+		sort.Ints(reservoir)
+		for _, i := range reservoir {
+			sampled.Rows = append(sampled.Rows, rows[i])
+		}
+		return sampled
+	}
+	for _, row := range rows {
+		if random.Float64() < opts.sample {
+			sampled.Rows = append(sampled.Rows, row)
+		}
+	}
+	return sampled
+}
+
+func sampleTables(tables []*graph.DataSet, opts exportOptions) []*graph.DataSet {
+	if opts.sample <= 0 && opts.sampleRows <= 0 {
+		return tables
+	}
+	sampled := make([]*graph.DataSet, len(tables))
+	for i, table := range tables {
+		sampled[i] = sampleTable(table, opts)
+	}
+	return sampled
+}
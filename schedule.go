@@ -0,0 +1,273 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The allowed values of a cron field, bit N set means value N matched
+type cronField uint64
+
+// The standard 5 fields cron expression: minute hour day-of-month month day-of-week
+type cronSpec struct {
+	minute, hour, dom, month, dow cronField
+	// Day of month and day of week are ORed if both are restricted like the Vixie cron
+	domAny, dowAny bool
+}
+
+// Parse the field like `*`, `*/15`, `1-5`, `0-30/10`, `1,3,5`
+func parseCronField(field string, min, max int) (cronField, error) {
+	var bits cronField
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			s, err := strconv.Atoi(part[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("Invalid step `%s'", part)
+			}
+			step = s
+			part = part[:i]
+		}
+		lo, hi := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, fmt.Errorf("Invalid value `%s'", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, fmt.Errorf("Invalid value `%s'", part)
+				}
+			} else if step != 1 {
+				// `5/15` means from 5 to the max by step 15
+				hi = max
+			}
+		}
+		if lo < min || hi > max || lo > hi {
+			return 0, fmt.Errorf("Value `%s' out of range %d-%d", part, min, max)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCron(expr string) (cronSpec, error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return cronSpec{}, fmt.Errorf("Expect 5 fields `minute hour day-of-month month day-of-week', got `%s'", expr)
+	}
+	var spec cronSpec
+	var err error
+	if spec.minute, err = parseCronField(fields[0], 0, 59); err != nil {
+		return spec, err
+	}
+	if spec.hour, err = parseCronField(fields[1], 0, 23); err != nil {
+		return spec, err
+	}
+	if spec.dom, err = parseCronField(fields[2], 1, 31); err != nil {
+		return spec, err
+	}
+	if spec.month, err = parseCronField(fields[3], 1, 12); err != nil {
+		return spec, err
+	}
+	// 0 and 7 are both Sunday
+	if spec.dow, err = parseCronField(fields[4], 0, 7); err != nil {
+		return spec, err
+	}
+	if spec.dow&(1<<7) != 0 {
+		spec.dow |= 1
+	}
+	spec.domAny = fields[2] == "*"
+	spec.dowAny = fields[4] == "*"
+	return spec, nil
+}
+
+func (f cronField) has(v int) bool {
+	return f&(1<<uint(v)) != 0
+}
+
+func (spec cronSpec) match(t time.Time) bool {
+	if !spec.minute.has(t.Minute()) || !spec.hour.has(t.Hour()) || !spec.month.has(int(t.Month())) {
+		return false
+	}
+	dom, dow := spec.dom.has(t.Day()), spec.dow.has(int(t.Weekday()))
+	if !spec.domAny && !spec.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// The statement executed on the cron schedule in the background
+type schedule struct {
+	id    int
+	expr  string
+	spec  cronSpec
+	stmt  string
+	space string
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+var (
+	schedulesMutex sync.Mutex
+	schedules      = map[int]*schedule{}
+	lastScheduleID = 0
+)
+
+func (s *schedule) log(format string, args ...interface{}) {
+	printBackground("[SCHEDULE #%d] %s %s", s.id, time.Now().Format("2006-01-02 15:04:05"), fmt.Sprintf(format, args...))
+}
+
+// Execute the statement by the dedicated session to not interfere the interactive one
+func (s *schedule) execute(session **Session) {
+	if *session == nil {
+		client, err := newSession(conn)
+		if err != nil {
+			s.log("Connect failed, %s", err.Error())
+			return
+		}
+		client.background = true
+		if s.space != "" {
+			resp, err := client.Execute("USE " + quoteName(s.space))
+			if err != nil {
+				client.Disconnect()
+				s.log("USE %s failed, %s", s.space, err.Error())
+				return
+			}
+			// Not executed in the space of the session instead
+			if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+				client.Disconnect()
+				s.log("USE %s failed, %s", s.space, errorString(resp))
+				return
+			}
+		}
+		*session = client
+	}
+	start := time.Now()
+	resp, err := (*session).Execute(s.stmt)
+	if err != nil {
+		s.log("`%s' error, %s", s.stmt, err.Error())
+		notify("schedule", s.stmt, "", err.Error())
+		(*session).Disconnect()
+		*session = nil
+		return
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		s.log("`%s' failed, %s", s.stmt, errorString(resp))
+		notify("schedule", s.stmt, resp.GetErrorCode().String(), string(resp.GetErrorMsg()))
+		return
+	}
+	s.log("`%s' succeeded in %s", s.stmt, time.Since(start).Round(time.Millisecond))
+}
+
+// Wake up at the beginning of each minute and execute if matched
+func (s *schedule) run() {
+	defer close(s.done)
+	var session *Session
+	defer func() {
+		if session != nil {
+			session.Disconnect()
+		}
+	}()
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if s.spec.match(next) {
+			s.execute(&session)
+		}
+	}
+}
+
+func closeSchedules() {
+	schedulesMutex.Lock()
+	defer schedulesMutex.Unlock()
+	for id, s := range schedules {
+		close(s.stop)
+		withoutTerminal(func() { <-s.done })
+		delete(schedules, id)
+	}
+}
+
+// :schedule "<cron>" '<statement>'
+var schedulePattern = regexp.MustCompile(`(?s)^"([^"]+)"\s+(?:'(.+)'|(.+))$`)
+
+// :schedule ["<cron>" '<statement>'|cancel <id>], list the schedules without arguments
+func scheduleCmd(client *Session, c Cli, args string) error {
+	args = strings.TrimSpace(args)
+	schedulesMutex.Lock()
+	defer schedulesMutex.Unlock()
+	if args == "" {
+		ids := make([]int, 0, len(schedules))
+		for id := range schedules {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+		for _, id := range ids {
+			s := schedules[id]
+			fmt.Printf("#%d \"%s\" `%s' in space `%s'", id, s.expr, s.stmt, s.space)
+			fmt.Println()
+		}
+		return nil
+	}
+	if fields := strings.Fields(args); fields[0] == "cancel" {
+		if len(fields) != 2 {
+			return fmt.Errorf("Usage: :schedule cancel <id>")
+		}
+		id, err := strconv.Atoi(strings.TrimPrefix(fields[1], "#"))
+		s, ok := schedules[id]
+		if err != nil || !ok {
+			return fmt.Errorf("Unknown schedule `%s'", fields[1])
+		}
+		close(s.stop)
+		withoutTerminal(func() { <-s.done })
+		delete(schedules, id)
+		return nil
+	}
+	m := schedulePattern.FindStringSubmatch(args)
+	if m == nil {
+		return fmt.Errorf("Usage: :schedule \"<minute hour day month weekday>\" '<statement>'")
+	}
+	spec, err := parseCron(m[1])
+	if err != nil {
+		return err
+	}
+	stmt := m[2]
+	if stmt == "" {
+		stmt = m[3]
+	}
+	if err = confirmStatement(c, stmt); err != nil {
+		return err
+	}
+	lastScheduleID++
+	s := &schedule{lastScheduleID, m[1], spec, strings.TrimSpace(stmt), client.space,
+		make(chan struct{}), make(chan struct{})}
+	schedules[s.id] = s
+	go s.run()
+	fmt.Printf("Scheduled #%d, cancel by `:schedule cancel %d'.", s.id, s.id)
+	fmt.Println()
+	return nil
+}
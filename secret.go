@@ -0,0 +1,76 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Mask the secret columns in the terminal output, changed by `:set mask_secrets`
+var maskSecrets = true
+
+const secretMask = "******"
+
+var secretColumnPattern = regexp.MustCompile(`(?i)passw(or)?d|token|secret`)
+
+// The statements with the password, never written to the tee file, journal, history, recording or webhook,
+// matched at the beginning of any statement after the comments, e.g. `USE s; CREATE USER u WITH PASSWORD 'x'`
+var secretStatementPattern = regexp.MustCompile(
+	`(?is)(?:^|;)(?:\s|--[^\n]*\n|//[^\n]*\n|#[^\n]*\n|/\*.*?\*/)*(CREATE\s+USER|ALTER\s+USER|CHANGE\s+PASSWORD)\b`)
+
+// The password of the statement continued from the previous line
+var passwordPattern = regexp.MustCompile(`(?i)\bPASSWORD\b`)
+
+// The `:connect <name> <address> <user> <password>` with the password, kept up to the user by the redaction
+var connectPasswordPattern = regexp.MustCompile(`^(\s*:connect\s+\S+\s+\S+\s+\S+)\s+\S`)
+
+// The columns to mask, nil if none
+func secretColumns(names []string) []bool {
+	if !maskSecrets || !stdoutIsTTY {
+		return nil
+	}
+	var secret []bool
+	for i, name := range names {
+		if secretColumnPattern.MatchString(name) {
+			if secret == nil {
+				secret = make([]bool, len(names))
+			}
+			secret[i] = true
+		}
+	}
+	return secret
+}
+
+func isSecretStatement(stmt string) bool {
+	return secretStatementPattern.MatchString(stmt) || passwordPattern.MatchString(stmt) ||
+		connectPasswordPattern.MatchString(stmt)
+}
+
+// Keep the statements before and the kind of the secret one only, e.g. `USE s; CREATE USER <redacted>`,
+// the rest is dropped since the password may have `;`
+func redactStatement(stmt string) string {
+	if m := connectPasswordPattern.FindStringSubmatch(stmt); m != nil {
+		return m[1] + " <redacted>"
+	}
+	m := secretStatementPattern.FindStringSubmatchIndex(stmt)
+	if m == nil {
+		if passwordPattern.MatchString(stmt) {
+			return "<redacted>"
+		}
+		return stmt
+	}
+	return stmt[:m[2]] + strings.ToUpper(strings.Join(strings.Fields(stmt[m[2]:m[3]]), " ")) + " <redacted>"
+}
+
+// The line of the secret statement, e.g. the continued one without the kind
+func redactLine(line string) string {
+	if redacted := redactStatement(line); redacted != line {
+		return redacted
+	}
+	return "<redacted>"
+}
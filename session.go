@@ -0,0 +1,390 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"time"
+
+	ngdb "github.com/shylock-hg/nebula-go2.0"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The connection parameters from command line, used to open extra sessions
+type Connection struct {
+	Address  string
+	Username string
+	Password string
+}
+
+var conn Connection
+
+// The flags to connect the server, shared by the subcommands
+type ConnectionFlags struct {
+	fs                    *flag.FlagSet
+	address               *string
+	port                  *int
+	username              *string
+	password              *string
+	enableSSL             *bool
+	sslRootCA             *string
+	sslCert               *string
+	sslKey                *string
+	sslInsecureSkipVerify *bool
+}
+
+func addConnectionFlags(fs *flag.FlagSet) *ConnectionFlags {
+	return &ConnectionFlags{
+		fs,
+		fs.String("address", "127.0.0.1", "The Nebula Graph IP address, or the comma separated graphd hosts like graphd-1,graphd-2:3700, the first is connected"),
+		fs.Int("port", 3699, "The Nebula Graph Port"),
+		fs.String("u", "user", "The Nebula Graph login user name"),
+		fs.String("p", "", "The Nebula Graph login password, prompt if omitted"),
+		fs.Bool("enable-ssl", false, "Connect to the Nebula Graph by TLS"),
+		fs.String("ssl-root-ca", "", "The root CA certificate file to verify the server"),
+		fs.String("ssl-cert", "", "The client certificate file"),
+		fs.String("ssl-key", "", "The client private key file"),
+		fs.Bool("ssl-insecure-skip-verify", false, "Skip verifying the server certificate"),
+	}
+}
+
+// Prompt the password if omitted and setup the TLS, called after parsing
+func (f *ConnectionFlags) Connection() (Connection, error) {
+	passwordSet := false
+	f.fs.Visit(func(fl *flag.Flag) {
+		if fl.Name == "p" {
+			passwordSet = true
+		}
+	})
+	if !passwordSet {
+		p, err := promptPassword()
+		if err != nil {
+			// e.g. stdin is not a terminal, pass it by -p
+			return Connection{}, codedError{exitUsageError, fmt.Errorf("Read password failed, %s", err.Error())}
+		}
+		*f.password = p
+	}
+	if *f.enableSSL {
+		c, err := newTLSConfig(*f.sslRootCA, *f.sslCert, *f.sslKey, *f.sslInsecureSkipVerify)
+		if err != nil {
+			return Connection{}, codedError{exitFileError, fmt.Errorf("Load TLS configuration failed, %s", err.Error())}
+		}
+		tlsConf = c
+	}
+	graphEndpoints = graphEndpoints[:0]
+	for _, host := range strings.Split(*f.address, ",") {
+		if host = strings.TrimSpace(host); host == "" {
+			continue
+		}
+		if !strings.Contains(host, ":") {
+			host = fmt.Sprintf("%s:%d", host, *f.port)
+		}
+		graphEndpoints = append(graphEndpoints, host)
+	}
+	if len(graphEndpoints) == 0 {
+		return Connection{}, codedError{exitUsageError, fmt.Errorf("No address")}
+	}
+	return Connection{graphEndpoints[0], *f.username, *f.password}, nil
+}
+
+// The timeout probing whether the server is reachable after the failed authentication
+const probeTimeout = 3 * time.Second
+
+// The client of the plaintext or the TLS connection, connected already
+type graphClient interface {
+	Execute(stmt string) (*graph.ExecutionResponse, error)
+	Disconnect()
+}
+
+// The client before authenticated
+type unconnectedClient interface {
+	graphClient
+	Connect(username, password string) error
+}
+
+// The client over TLS if `--enable-ssl`
+func newClient(address string) (unconnectedClient, error) {
+	if tlsConf != nil {
+		return newTLSClient(address, tlsConf)
+	}
+	return ngdb.NewClient(address)
+}
+
+// The errors are coded by exitConnectError or exitAuthError
+func connectClient(c Connection) (graphClient, error) {
+	client, err := newClient(c.Address)
+	if err != nil {
+		return nil, codedError{exitConnectError, err}
+	}
+	if err = client.Connect(c.Username, c.Password); err != nil {
+		client.Disconnect()
+		// The client fails the same way by either, so the reachable server rejected the credentials
+		probe, dialErr := net.DialTimeout("tcp", c.Address, probeTimeout)
+		if dialErr != nil {
+			return nil, codedError{exitConnectError, err}
+		}
+		probe.Close()
+		return nil, codedError{exitAuthError, err}
+	}
+	return client, nil
+}
+
+// The client could be replaced by reconnecting, the current space is restored then
+type Session struct {
+	conn   Connection
+	client graphClient
+	space  string
+	// Used by the background goroutine, e.g. the keepalive, the notices are printed above the prompt
+	background bool
+}
+
+// Open a new session to the server
+func newSession(c Connection) (*Session, error) {
+	client, err := connectClient(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Session{c, client, "", false}, nil
+}
+
+func (s *Session) Execute(stmt string) (*graph.ExecutionResponse, error) {
+	if queryTimeout > 0 {
+		return s.executeAbandonable(stmt, nil)
+	}
+	resp, err := s.client.Execute(stmt)
+	return s.finish(stmt, resp, err)
+}
+
+func isSessionExpired(code graph.ErrorCode) bool {
+	return code == graph.ErrorCode_E_SESSION_INVALID || code == graph.ErrorCode_E_SESSION_TIMEOUT
+}
+
+// Reconnect and retry once if the session expired, and track the current space
+func (s *Session) finish(stmt string, resp *graph.ExecutionResponse, err error) (*graph.ExecutionResponse, error) {
+	if err == nil && isSessionExpired(resp.GetErrorCode()) {
+		s.notice("[NOTICE] Session expired, reconnecting to %s.", s.conn.Address)
+		if e := s.Reconnect(); e != nil {
+			return nil, fmt.Errorf("Reconnect failed, %s", e.Error())
+		}
+		resp, err = s.client.Execute(stmt)
+	}
+	// The failed statement keeps the space unless reported, e.g. `USE s; <failed>` switched already
+	if err == nil && (resp.GetErrorCode() == graph.ErrorCode_SUCCEEDED || len(resp.SpaceName) > 0) {
+		s.space = string(resp.SpaceName)
+	}
+	return resp, err
+}
+
+func (s *Session) notice(format string, args ...interface{}) {
+	if s.background {
+		printBackground(format, args...)
+		return
+	}
+	fmt.Printf(format, args...)
+	fmt.Println()
+}
+
+var errInterrupted = fmt.Errorf("Interrupted")
+
+// Wait for the response at most, 0 means no limit
+var queryTimeout time.Duration
+
+var errTimeout = fmt.Errorf("Timeout")
+
+// The statement abandoned by errInterrupted or errTimeout, and the client failed to reconnect then
+type abandonError struct {
+	reason    error
+	reconnect error
+}
+
+func (e abandonError) Error() string {
+	return fmt.Sprintf("%s, reconnect failed, %s", e.reason.Error(), e.reconnect.Error())
+}
+
+func (e abandonError) Unwrap() error {
+	return e.reason
+}
+
+// The failure of reconnecting after the statement abandoned, nil if reconnected
+func reconnectFailure(err error) error {
+	var abandoned abandonError
+	if errors.As(err, &abandoned) {
+		return abandoned.reconnect
+	}
+	return nil
+}
+
+// Execute and wait util finished or Ctrl+C
+func (s *Session) ExecuteInterruptible(stmt string) (*graph.ExecutionResponse, error) {
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	return s.executeAbandonable(stmt, interrupt)
+}
+
+// Execute and wait util finished, interrupted or timeout, the client is abandoned then
+// since the pending response would be read by the following requests
+func (s *Session) executeAbandonable(stmt string, interrupt <-chan os.Signal) (*graph.ExecutionResponse, error) {
+	type result struct {
+		resp *graph.ExecutionResponse
+		err  error
+	}
+	done := make(chan result, 1)
+	client := s.client
+	go func() {
+		resp, err := client.Execute(stmt)
+		done <- result{resp, err}
+	}()
+
+	var timeout <-chan time.Time
+	if queryTimeout > 0 {
+		timer := time.NewTimer(queryTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	abandoned := errInterrupted
+	select {
+	case r := <-done:
+		return s.finish(stmt, r.resp, r.err)
+	case <-interrupt:
+	case <-timeout:
+		abandoned = errTimeout
+	}
+	// Disconnected after the pending response instead of by reconnecting
+	go func() {
+		<-done
+		client.Disconnect()
+	}()
+	if err := s.connect(); err != nil {
+		return nil, abandonError{abandoned, err}
+	}
+	return nil, abandoned
+}
+
+// :timeout [duration], show or set the query timeout, 0 means no limit
+func timeoutCmd(client *Session, c Cli, args string) error {
+	args = strings.TrimSpace(args)
+	if args == "" {
+		if queryTimeout == 0 {
+			fmt.Println("No query timeout.")
+		} else {
+			fmt.Printf("Query timeout %s.", queryTimeout)
+			fmt.Println()
+		}
+		return nil
+	}
+	return durationSetting(&queryTimeout).set(args)
+}
+
+// Replace the client by a new connected one, and restore the current space,
+// the replaced one is disconnected to not leak its session in the server
+func (s *Session) Reconnect() error {
+	replaced := s.client
+	err := s.connect()
+	if s.client != replaced {
+		replaced.Disconnect()
+	}
+	return err
+}
+
+// Connect the new client as the current one, the replaced one is left to the caller
+func (s *Session) connect() error {
+	client, err := connectClient(s.conn)
+	if err != nil {
+		return err
+	}
+	s.client = client
+	if s.space != "" {
+		resp, err := client.Execute("USE " + quoteName(s.space))
+		if err != nil {
+			return err
+		}
+		if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			return fmt.Errorf("Restore space %s failed, %s", s.space, errorString(resp))
+		}
+	}
+	return nil
+}
+
+func (s *Session) Disconnect() {
+	s.client.Disconnect()
+}
+
+// The named sessions opened by `:connect`, the startup one is `default`
+var sessions = map[string]*Session{}
+
+func closeSessions() {
+	for name, session := range sessions {
+		if name != defaultSession {
+			session.Disconnect()
+		}
+	}
+	for _, session := range hostSessions {
+		session.Disconnect()
+	}
+}
+
+const defaultSession = "default"
+
+// :connect <name> <address> [user [password]]
+func connectCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) < 2 || len(fields) > 4 {
+		return fmt.Errorf("Usage: :connect <name> <address> [user [password]]")
+	}
+	if _, ok := sessions[fields[0]]; ok {
+		return fmt.Errorf("Session `%s' already exists", fields[0])
+	}
+	param := Connection{fields[1], conn.Username, conn.Password}
+	if len(fields) > 2 {
+		param.Username = fields[2]
+	}
+	if len(fields) > 3 {
+		param.Password = fields[3]
+	}
+	session, err := newSession(param)
+	if err != nil {
+		return err
+	}
+	sessions[fields[0]] = session
+	return nil
+}
+
+// :disconnect <name>
+func disconnectCmd(client *Session, c Cli, args string) error {
+	name := strings.TrimSpace(args)
+	session, ok := sessions[name]
+	if !ok {
+		return fmt.Errorf("Session `%s' not found", name)
+	}
+	if name == defaultSession {
+		return fmt.Errorf("Can't disconnect the default session")
+	}
+	session.Disconnect()
+	delete(sessions, name)
+	return nil
+}
+
+// :sessions
+func sessionsCmd(client *Session, c Cli, args string) error {
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// The console option changed by `:set <name> <value>`
+type setting struct {
+	get func() string
+	set func(string) error
+}
+
+func intSetting(v *int) setting {
+	return setting{
+		func() string { return strconv.Itoa(*v) },
+		func(s string) error {
+			i, err := strconv.Atoi(s)
+			if err != nil || i < 0 {
+				return fmt.Errorf("Expect a non-negative integer, got `%s'", s)
+			}
+			*v = i
+			return nil
+		},
+	}
+}
+
+func choiceSetting(v *string, choices ...string) setting {
+	return setting{
+		func() string { return *v },
+		func(s string) error {
+			if !contains(choices, s) {
+				return fmt.Errorf("Expect one of %s, got `%s'", strings.Join(choices, ", "), s)
+			}
+			*v = s
+			return nil
+		},
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func boolSetting(v *bool) setting {
+	return setting{
+		func() string { return onOff(*v) },
+		func(s string) error {
+			switch strings.ToLower(s) {
+			case "on", "true", "1":
+				*v = true
+			case "off", "false", "0":
+				*v = false
+			default:
+				return fmt.Errorf("Expect on or off, got `%s'", s)
+			}
+			return nil
+		},
+	}
+}
+
+// Show the first N items of list/map/set values, 0 means no limit
+var maxCollectionItems = 0
+
+// How to export the non-UTF8 strings
+var binaryStrings = "escape"
+
+var settings = map[string]setting{
+	"max_collection_items": intSetting(&maxCollectionItems),
+	"auto_quote":           boolSetting(&autoQuote),
+	"use_banner":           boolSetting(&useBanner),
+	"binary_strings":       choiceSetting(&binaryStrings, binaryModes...),
+	"color":                choiceSetting(&colorMode, colorModes...),
+	"hyperlinks":           choiceSetting(&hyperlinkMode, colorModes...),
+	"timezone":             timezoneSetting(),
+	"max_result_memory":    byteSizeSetting(&maxResultMemory),
+	"page_rows":            intSetting(&pageRows),
+	"max_rows":             intSetting(&maxRows),
+	"mask_secrets":         boolSetting(&maskSecrets),
+	"expand_props":         boolSetting(&expandProps),
+	"max_col_width":        intSetting(&maxColWidth),
+	"col_overflow":         choiceSetting(&colOverflow, colOverflows...),
+	"keepalive":            durationSetting(&keepaliveInterval),
+	"highlight":            boolSetting(&highlightInput),
+	"autosuggest":          boolSetting(&autoSuggest),
+	"show_resource_usage":  boolSetting(&showResourceUsage),
+}
+
+// The edits between the strings, to catch the typos of the setting names
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+// The setting the name is likely a typo of, e.g. `max_row` of `max_rows`, empty if none
+func similarSetting(name string) string {
+	name = strings.ToLower(name)
+	similar, distance := "", 3
+	for s := range settings {
+		if d := editDistance(name, s); d < distance || (d == distance && s < similar) {
+			similar, distance = s, d
+		}
+	}
+	if distance > 2 {
+		return ""
+	}
+	return similar
+}
+
+// :set [<name> <value>], the unknown name is set as the variable referenced by `${name}`,
+// unless it's close to a setting, which is set as the variable by `:let` explicitly
+func setCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		names := make([]string, 0, len(settings))
+		for name := range settings {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s = %s", name, settings[name].get())
+			fmt.Println()
+		}
+		names = names[:0]
+		for name := range variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("${%s} = %s", name, variables[name])
+			fmt.Println()
+		}
+		return nil
+	}
+	if len(fields) < 2 {
+		return fmt.Errorf("Usage: :set <name> <value>")
+	}
+	if s, ok := settings[strings.ToLower(fields[0])]; ok {
+		if len(fields) != 2 {
+			return fmt.Errorf("Usage: :set <name> <value>")
+		}
+		return s.set(fields[1])
+	}
+	if similar := similarSetting(fields[0]); similar != "" {
+		return fmt.Errorf("Unknown setting `%s', did you mean `%s'? Set the variable by `:let %s <value>'", fields[0], similar, fields[0])
+	}
+	return letCmd(client, c, args)
+}
+
+// :let <name> <value>, set the variable referenced by `${name}`
+func letCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) < 2 {
+		return fmt.Errorf("Usage: :let <name> <value>")
+	}
+	// The variable value keeps the inner spaces, e.g. `:let vids "a", "b"`
+	return paramFlag{}.Set(fields[0] + "=" + strings.TrimSpace(strings.TrimSpace(args)[len(fields[0]):]))
+}
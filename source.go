@@ -0,0 +1,61 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Read the statements from the script, but report to the console which sourced it
+type sourceCli struct {
+	nCli
+	parent Cli
+}
+
+// Keep the interactive behaviors, e.g. cancel by Ctrl+C and reconnect instead of exit
+func (l sourceCli) Interactive() bool {
+	return l.parent.Interactive()
+}
+
+func (l sourceCli) SetSpace(space string) {
+	l.parent.SetSpace(space)
+}
+
+func (l sourceCli) SetisErr(isErr bool) {
+	l.parent.SetisErr(isErr)
+}
+
+// Limit the nested `:source` to avoid the infinite recursion
+const maxSourceDepth = 16
+
+var sourceDepth = 0
+
+// :source <file>, execute the script in the current session
+func sourceCmd(client *Session, c Cli, args string) error {
+	file := os.ExpandEnv(strings.TrimSpace(args))
+	if file == "" {
+		return fmt.Errorf("Usage: :source <file>")
+	}
+	if sourceDepth >= maxSourceDepth {
+		return fmt.Errorf("Too deep nested `:source', exceeds %d", maxSourceDepth)
+	}
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	sourceDepth++
+	defer func() { sourceDepth-- }()
+	return loop(client, sourceCli{NewnCli(fd), c})
+}
+
+// Registered here since `loop` refers to the console commands
+func init() {
+	consoleCommands["source"] = sourceCmd
+}
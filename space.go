@@ -0,0 +1,95 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Print the space metadata after USE succeeded
+var useBanner = false
+
+var usePattern = regexp.MustCompile(`(?is)^\s*USE\s+\S+\s*;?\s*$`)
+
+// The `DESCRIBE SPACE` result, keyed by the lower case column name with `_`, e.g. `partition_number`
+func describeSpace(client *Session, space string) (map[string]string, error) {
+	resp, err := client.Execute("DESCRIBE SPACE " + quoteName(space))
+	if err != nil {
+		return nil, err
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return nil, fmt.Errorf("DESCRIBE SPACE failed, %s", errorString(resp))
+	}
+	desc := map[string]string{}
+	for _, table := range resp.GetData() {
+		for _, row := range table.GetRows() {
+			for i, name := range columnNames(table) {
+				desc[strings.ToLower(strings.Replace(name, " ", "_", -1))] = exportValue(row.GetColumns()[i])
+			}
+		}
+	}
+	return desc, nil
+}
+
+// The value of the first column whose name contains the key
+func descValue(desc map[string]string, key string) string {
+	for name, value := range desc {
+		if strings.Contains(name, key) {
+			return value
+		}
+	}
+	return "unknown"
+}
+
+// The rows count of `SHOW TAGS` or `SHOW EDGES`
+func schemaCount(client *Session, stmt string) string {
+	names := schemaNames(client, stmt)
+	return fmt.Sprintf("%d", len(names))
+}
+
+func printSpaceBanner(client *Session, space string) {
+	desc, err := describeSpace(client, space)
+	if err != nil {
+		fmt.Printf("[WARNING] %s", err.Error())
+		fmt.Println()
+		return
+	}
+	fmt.Printf("Space %s: %s partitions, replica factor %s, vid type %s, %s tags, %s edges.",
+		space, descValue(desc, "partition"), descValue(desc, "replica"), descValue(desc, "vid"),
+		schemaCount(client, "SHOW TAGS"), schemaCount(client, "SHOW EDGES"))
+	fmt.Println()
+}
+
+var plainNamePattern = regexp.MustCompile(`^\w+$`)
+
+// :use <space>, validated by SHOW SPACES before USE
+func useCmd(client *Session, c Cli, args string) error {
+	space := strings.Trim(strings.TrimSpace(args), "`;")
+	if space == "" {
+		return fmt.Errorf("Usage: :use <space>")
+	}
+	spaces, err := foreachSpaces(client, "SHOW SPACES")
+	if err != nil {
+		return err
+	}
+	if !contains(spaces, space) {
+		for _, s := range spaces {
+			if strings.EqualFold(s, space) {
+				return fmt.Errorf("Space `%s' not found, did you mean `%s'?", space, s)
+			}
+		}
+		return fmt.Errorf("Space `%s' not found in %d spaces, see SHOW SPACES", space, len(spaces))
+	}
+	if !plainNamePattern.MatchString(space) {
+		space = "`" + space + "`"
+	}
+	return execute(client, c, fmt.Sprintf("USE %s;", space))
+}
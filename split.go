@@ -0,0 +1,94 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The numbered part before the extension, e.g. out-0001.csv
+func partPath(path string, n int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%04d%s", strings.TrimSuffix(path, ext), n, ext)
+}
+
+// The encoded bytes of the table, the header included
+func encodedSize(table *graph.DataSet, format string, opts exportOptions) int64 {
+	var buf bytes.Buffer
+	writeTable(&buf, table, format, opts)
+	return int64(buf.Len())
+}
+
+// Split the rows by the options, each part has the header
+func splitTable(table *graph.DataSet, format string, opts exportOptions) []*graph.DataSet {
+	parts := []*graph.DataSet{}
+	var part *graph.DataSet
+	var size, headerSize int64
+	if opts.splitSize > 0 {
+		headerSize = encodedSize(&graph.DataSet{ColumnNames: table.GetColumnNames()}, format, opts)
+	}
+	for _, row := range table.GetRows() {
+		var rowSize int64
+		if opts.splitSize > 0 {
+			// Excluding the header
+			single := &graph.DataSet{ColumnNames: table.GetColumnNames(), Rows: []*graph.Row{row}}
+			rowSize = encodedSize(single, format, opts) - headerSize
+		}
+		if part == nil || (opts.splitRows > 0 && len(part.Rows) >= opts.splitRows) ||
+			(opts.splitSize > 0 && len(part.Rows) > 0 && size+rowSize > opts.splitSize) {
+			part = &graph.DataSet{ColumnNames: table.GetColumnNames()}
+			parts = append(parts, part)
+			size = 0
+		}
+		part.Rows = append(part.Rows, row)
+		size += rowSize
+	}
+	if len(parts) == 0 {
+		// The header only
+		parts = append(parts, &graph.DataSet{ColumnNames: table.GetColumnNames()})
+	}
+	return parts
+}
+
+// Export to the numbered files of the file sink
+func exportParts(sink Sink, tables []*graph.DataSet, opts exportOptions) error {
+	if sink.Type != "" && sink.Type != "file" {
+		return fmt.Errorf("Split requires the file sink, got `%s'", sink.Type)
+	}
+	path := expandDestination(sink.Path)
+	rows, n := 0, 0
+	for i, table := range tables {
+		if len(tables) > 1 {
+			// Tell the results apart like the single file
+			table = withResultColumn(table, i+1)
+		}
+		for _, part := range splitTable(table, sink.Format, opts) {
+			n++
+			fd, err := createFile(partPath(path, n), sink.Compress)
+			if err != nil {
+				return codedError{exitFileError, err}
+			}
+			if err = writeTable(fd, part, sink.Format, opts); err != nil {
+				fd.Close()
+				return codedError{exitFileError, err}
+			}
+			if err = fd.Close(); err != nil {
+				return codedError{exitFileError, err}
+			}
+			rows += len(part.GetRows())
+		}
+	}
+	fmt.Fprintf(out, "Exported %d rows to %d parts `%s' ... `%s'.", rows, n,
+		compressedPath(partPath(path, 1), sink.Compress), compressedPath(partPath(path, n), sink.Compress))
+	fmt.Fprintln(out)
+	return nil
+}
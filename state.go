@@ -0,0 +1,79 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// The console state persisted across runs
+type State struct {
+	// The last used space of each `user@address`
+	Spaces map[string]string `json:"spaces"`
+}
+
+var stateFile = ""
+
+var state = &State{map[string]string{}}
+
+func loadState(file string) {
+	stateFile = file
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return
+	}
+	s := &State{}
+	if json.Unmarshal(content, s) == nil && s.Spaces != nil {
+		state = s
+	}
+}
+
+func saveState() {
+	if stateFile == "" {
+		return
+	}
+	content, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return
+	}
+	if err = ioutil.WriteFile(stateFile, content, 0600); err != nil {
+		fmt.Printf("[WARNING] Save state to %s failed, %s", stateFile, err.Error())
+		fmt.Println()
+	}
+}
+
+func profileName(c Connection) string {
+	return fmt.Sprintf("%s@%s", c.Username, c.Address)
+}
+
+// Save the current space if changed
+func rememberSpace(client *Session) {
+	profile := profileName(client.conn)
+	if client.space == "" || state.Spaces[profile] == client.space {
+		return
+	}
+	state.Spaces[profile] = client.space
+	saveState()
+}
+
+// Offer to USE the last used space at startup
+func offerLastSpace(client *Session, c Cli) {
+	space, ok := state.Spaces[profileName(client.conn)]
+	if !ok || space == "" {
+		return
+	}
+	answer, err, exit := c.Ask(fmt.Sprintf("Use the last space %s? (y/n) [y]: ", space))
+	if err != nil || exit {
+		return
+	}
+	if answer = strings.ToLower(strings.TrimSpace(answer)); answer == "" || answer == "y" {
+		execute(client, c, "USE "+quoteName(space))
+	}
+}
@@ -3,99 +3,206 @@ package main
 import (
 	"strconv"
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	common "github.com/shylock-hg/nebula-go2.0/nebula"
 	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
 )
 
+// The suffix of the truncated collection
+func moreItems(n int) string {
+	return fmt.Sprintf("… (+%d more)", n)
+}
+
+// Print the properties of the vertices and edges, changed by `:set expand_props`
+var expandProps = false
+
+// How the values are rendered, the display follows the settings while the exports,
+// the comparisons and the hashes need the same text regardless of the settings
+type valueOptions struct {
+	// The items of the collections, 0 means all
+	maxItems    int
+	expandProps bool
+	// nil keeps the zone from the server
+	location *time.Location
+	// Round the floats to hide the noise of the computation, by `--canonical`
+	roundFloats bool
+}
+
+// By `:set max_collection_items`, `expand_props` and `timezone`
+func displayOptions() valueOptions {
+	return valueOptions{maxItems: maxCollectionItems, expandProps: expandProps, location: displayLocation}
+}
+
+// All the items in the zone from the server, the vertices and edges by the ids
+var exactOptions = valueOptions{}
+
+// {name: value, ...} sorted by name
+func writeProps(b *strings.Builder, props map[string]*common.Value, depth uint, opts valueOptions) {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(name)
+		b.WriteString(": ")
+		writeValue(b, props[name], depth, opts)
+	}
+	b.WriteByte('}')
+}
+
+// The value displayed in the table
 func val2String(value *common.Value, depth uint) string {
+	var b strings.Builder
+	writeValue(&b, value, depth, displayOptions())
+	return b.String()
+}
+
+// The value independent of the display settings
+func exactString(value *common.Value) string {
+	var b strings.Builder
+	writeValue(&b, value, 256, exactOptions)
+	return b.String()
+}
+
+// Write the value to the builder, the collections are written in place
+// instead of concatenating the strings of the items
+func writeValue(b *strings.Builder, value *common.Value, depth uint, opts valueOptions) {
 	// TODO(shylock) get golang runtime limit
 	if depth == 0 {  // Avoid too deep recursive
-		return "..."
+		b.WriteString("...")
+		return
 	}
 
 	if value.IsSetNVal() {  // null
 		switch value.GetNVal() {
 		case common.NullType___NULL__:
-			return "NULL"
+			b.WriteString("NULL")
 		case common.NullType_NaN:
-			return "NaN"
+			b.WriteString("NaN")
 		case common.NullType_BAD_DATA:
-			return "BAD_DATA"
+			b.WriteString("BAD_DATA")
 		case common.NullType_BAD_TYPE:
-			return "BAD_TYPE"
+			b.WriteString("BAD_TYPE")
 		}
 	} else if value.IsSetBVal() {  // bool
-		return strconv.FormatBool(value.GetBVal())
+		b.WriteString(strconv.FormatBool(value.GetBVal()))
 	} else if value.IsSetIVal() {  // int64
-		return strconv.FormatInt(value.GetIVal(), 10)
+		b.WriteString(strconv.FormatInt(value.GetIVal(), 10))
 	} else if value.IsSetFVal() {  // float64
-		return strconv.FormatFloat(value.GetFVal(), 'g', -1, 64)
+		if opts.roundFloats {
+			b.WriteString(canonicalFloat(value.GetFVal()))
+		} else {
+			b.WriteString(strconv.FormatFloat(value.GetFVal(), 'g', -1, 64))
+		}
 	} else if value.IsSetSVal() {  // string
-		return "\"" + string(value.GetSVal()) + "\""
+		b.WriteByte('"')
+		b.Write(value.GetSVal())
+		b.WriteByte('"')
 	} else if value.IsSetDVal() {  // yyyy-mm-dd
-		date := value.GetDVal()
-		str := fmt.Sprintf("%d-%d-%d", date.GetYear(), date.GetMonth(), date.GetDay())
-		return str
-	} else if value.IsSetTVal() {  // yyyy-mm-dd HH:MM:SS:MS TZ
-		datetime := value.GetTVal()
-		// TODO(shylock) timezone
-		str := fmt.Sprintf("%d-%d-%d %d:%d:%d:%d",
-			datetime.GetYear(), datetime.GetMonth(), datetime.GetDay(),
-			datetime.GetHour(), datetime.GetMinute(), datetime.GetSec(), datetime.GetMicrosec())
-		return str
+		b.WriteString(formatDate(value.GetDVal()))
+	} else if value.IsSetTVal() {  // yyyy-mm-ddTHH:MM:SS.ssssss+TZ
+		b.WriteString(formatDateTime(value.GetTVal(), opts.location))
 	} else if value.IsSetVVal() {  // Vertex
-		// VId only
-		return string(value.GetVVal().GetVid())
+		// VId only, or vid :tag{prop: value} :tag{...} if expanded
+		vertex := value.GetVVal()
+		b.Write(vertex.GetVid())
+		if opts.expandProps {
+			for _, tag := range vertex.GetTags() {
+				b.WriteString(" :")
+				b.Write(tag.GetName())
+				writeProps(b, tag.GetProps(), depth - 1, opts)
+			}
+		}
 	} else if value.IsSetEVal() {  // Edge
-		// src-[TypeName]->dst@ranking
+		// src-[TypeName]->dst@ranking, with {prop: value} if expanded
 		edge := value.GetEVal()
-		return fmt.Sprintf("%s-[%s]->%s@%d", string(edge.GetSrc()), edge.GetName(), string(edge.GetDst()),
-			edge.GetRanking())
+		writeStep(b, edge.GetSrc(), edge.GetName(), edge.GetDst(), edge.GetRanking())
+		if opts.expandProps {
+			writeProps(b, edge.GetProps(), depth - 1, opts)
+		}
 	} else if value.IsSetPVal() {  // Path
 		// src-[TypeName]->dst@ranking-[TypeName]->dst@ranking ...
 		p := value.GetPVal()
-		str := string(p.GetSrc().GetVid())
+		b.Write(p.GetSrc().GetVid())
 		for _, step := range p.GetSteps() {
-			pStr := fmt.Sprintf("-[%s]->%s@%d", step.GetName(), string(step.GetDst().GetVid()), step.GetRanking())
-			str += pStr
+			writeStep(b, nil, step.GetName(), step.GetDst().GetVid(), step.GetRanking())
 		}
-		return str
 	} else if value.IsSetLVal() {  // List
-		// TODO(shylock) optimize the recursive
-		l := value.GetLVal()
-		str := "["
-		for _, v := range l.GetValues() {
-			str += val2String(v, depth - 1)
-			str += ","
-		}
-		str += "]"
-		return str
+		writeItems(b, "[", value.GetLVal().GetValues(), "]", depth, opts)
 	} else if value.IsSetMVal() {  // Map
-		// TODO(shylock) optimize the recursive
-		m := value.GetMVal()
-		str := "{"
-		for k, v := range m.GetKvs() {
-			str += "\"" + k + "\""
-			str += ":"
-			str += val2String(v, depth - 1)
-			str += ","
-		}
-		str += "}"
-		return str
+		// Sorted by the key, the same map is always the same text
+		kvs := value.GetMVal().GetKvs()
+		keys := make([]string, 0, len(kvs))
+		for k := range kvs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for i, k := range keys {
+			if opts.maxItems > 0 && i >= opts.maxItems {
+				b.WriteString(moreItems(len(keys) - i))
+				break
+			}
+			b.WriteByte('"')
+			b.WriteString(k)
+			b.WriteString("\":")
+			writeValue(b, kvs[k], depth - 1, opts)
+			b.WriteByte(',')
+		}
+		b.WriteByte('}')
 	} else if value.IsSetUVal() {  // Set
-		// TODO(shylock) optimize the recursive
-		s := value.GetUVal()
-		str := "{"
-		for _, v := range s.GetValues() {
-			str += val2String(v, depth - 1)
-			str += ","
+		writeItems(b, "{", value.GetUVal().GetValues(), "}", depth, opts)
+	}
+}
+
+// src-[TypeName]->dst@ranking, without the src for the path steps
+func writeStep(b *strings.Builder, src []byte, name []byte, dst []byte, ranking common.EdgeRanking) {
+	b.Write(src)
+	b.WriteString("-[")
+	b.Write(name)
+	b.WriteString("]->")
+	b.Write(dst)
+	b.WriteByte('@')
+	b.WriteString(strconv.FormatInt(int64(ranking), 10))
+}
+
+// The items of the list or set, each followed by `,`
+func writeItems(b *strings.Builder, open string, values []*common.Value, close string, depth uint, opts valueOptions) {
+	b.WriteString(open)
+	for i, v := range values {
+		if opts.maxItems > 0 && i >= opts.maxItems {
+			b.WriteString(moreItems(len(values) - i))
+			break
 		}
-		str += "}"
-		return str
+		writeValue(b, v, depth - 1, opts)
+		b.WriteByte(',')
 	}
-	return ""
+	b.WriteString(close)
+}
+
+func columnNames(table *graph.DataSet) []string {
+	names := make([]string, len(table.GetColumnNames()))
+	for i, name := range table.GetColumnNames() {
+		names[i] = string(name)
+	}
+	return names
+}
+
+// The footer of the table
+func printRowCount(rows int, columns int) {
+	if quiet {
+		return
+	}
+	fmt.Fprintf(out, "Got %d rows, %d columns.", rows, columns)
+	fmt.Fprintln(out)
 }
 
 func max(v1 uint, v2 uint) uint {
@@ -126,49 +233,237 @@ func NewTable(align uint, header string, row string, delemiter string) Table {
 
 // Columns width
 type TableSpec = []uint
-type TableRows = [][]string
 
-func (t Table) printRow(row []string, colSpec TableSpec) {
+// The colors and links are applied after padding to keep the width, nil values for the plain row
+// The line is built then written once instead of per cell
+func (t Table) printRow(row []string, values []*common.Value, colSpec TableSpec) {
+	var line strings.Builder
+	line.Grow(int(sum(colSpec)) + len(row) * (int(t.align) * 2 + 1) + 2)
+	indent := strings.Repeat(" ", int(t.align))
 	for i, col := range row {
-		colString := "|" + strings.Repeat(" ", int(t.align)) + col;
-		length := uint(len(col))
+		length := uint(stringWidth(col))
+		if values != nil {
+			col = decorate(col, values[i])
+		}
+		line.WriteByte('|')
+		line.WriteString(indent)
+		line.WriteString(col)
 		if length < colSpec[i] + t.align {
-			colString = colString + strings.Repeat(" ", int(colSpec[i]+t.align - length))
+			line.WriteString(strings.Repeat(" ", int(colSpec[i]+t.align - length)))
 		}
-		fmt.Print(colString)
 	}
-	fmt.Println("|")
+	line.WriteString("|\n")
+	fmt.Fprint(out, line.String())
+}
+
+// Print the rows by pages with the header repeated, 0 means one page
+var pageRows = 0
+
+// Render the first rows of each table only, 0 means no limit, changed by `:set max_rows` or `--max-rows`
+var maxRows = 0
+
+// The table of the first maxRows rows, and the rows not rendered
+func limitRows(table *graph.DataSet) (*graph.DataSet, int) {
+	if maxRows <= 0 || len(table.GetRows()) <= maxRows {
+		return table, 0
+	}
+	return &graph.DataSet{ColumnNames: table.GetColumnNames(), Rows: table.GetRows()[:maxRows]}, len(table.GetRows()) - maxRows
+}
+
+// Limit the columns width, 0 means no limit, the long cells are truncated or wrapped by colOverflow
+var maxColWidth = 0
+
+var colOverflows = []string{"truncate", "wrap"}
+
+var colOverflow = "truncate"
+
+// Print the row in multiple lines if wrapped, each cell is soft-wrapped in the column width
+func (t Table) printCells(row []string, values []*common.Value, colSpec TableSpec, wrap bool) {
+	if !wrap {
+		t.printRow(row, values, colSpec)
+		return
+	}
+	lines := make([][]string, len(row))
+	height := 1
+	for j, col := range row {
+		lines[j] = wrapWidth(col, int(colSpec[j]))
+		if len(lines[j]) > height {
+			height = len(lines[j])
+		}
+	}
+	line := make([]string, len(row))
+	for k := 0; k < height; k++ {
+		for j := range row {
+			line[j] = ""
+			if k < len(lines[j]) {
+				line[j] = lines[j][k]
+			}
+		}
+		t.printRow(line, values, colSpec)
+	}
 }
 
 func (t Table) PrintTable(table *graph.DataSet) {
+	t.printTable(table, pageRows)
+}
+
+// Two passes to keep the memory bounded, the first pass calculates the columns width
+// and the second one formats and prints row by row
+// In pages, the width is learned from the first page and kept for the later pages to not jitter,
+// the wider values are truncated until re-flowed by `:reflow`
+func (t Table) printTable(table *graph.DataSet, pageRows int) {
+	// Flushed per table before the messages printed to stdout directly
+	out.buffer()
+	defer out.unbuffer()
 	columnSize := len(table.GetColumnNames())
 	rowSize := len(table.GetRows())
 	tableSpec := make(TableSpec, columnSize)
-	tableRows := make(TableRows, rowSize)
-	tableHeader := make([]string, columnSize)
-	for i, header := range table.GetColumnNames() {
-		tableSpec[i] = uint(len(header))
-		tableHeader[i] = string(header)
+	tableHeader := columnNames(table)
+	for i, header := range tableHeader {
+		tableSpec[i] = uint(stringWidth(header))
 	}
-	for i, row := range table.GetRows() {
-		tableRows[i] = make([]string, columnSize)
+	secret := secretColumns(tableHeader)
+	cell := func(j int, col *common.Value) string {
+		if secret != nil && secret[j] {
+			return secretMask
+		}
+		return val2String(col, 256)
+	}
+	learned := table.GetRows()
+	paged := pageRows > 0 && pageRows < rowSize
+	if paged {
+		learned = learned[:pageRows]
+	}
+	for _, row := range learned {
 		for j, col := range row.GetColumns() {
-			tableRows[i][j] = val2String(col, 256)
-			tableSpec[j] = max(uint(len(tableRows[i][j])), tableSpec[j])
+			tableSpec[j] = max(uint(stringWidth(cell(j, col))), tableSpec[j])
+		}
+	}
+	if maxColWidth > 0 {
+		for j := range tableSpec {
+			if tableSpec[j] > uint(maxColWidth) {
+				tableSpec[j] = uint(maxColWidth)
+			}
+		}
+	}
+	wrap := maxColWidth > 0 && colOverflow == "wrap"
+	// Fit the cell to the column width, only the first line if not wrapped
+	fit := func(j int, s string) string {
+		if !wrap && int(tableSpec[j]) < stringWidth(s) {
+			return truncateWidth(s, int(tableSpec[j]))
 		}
+		return s
 	}
 
 	//                 value limit         + two indent              + '|' itself
 	totalLineLength := int(sum(tableSpec)) + columnSize * int(t.align) * 2  + columnSize + 1
 	headerLine := strings.Repeat(t.headerChar, totalLineLength)
 	rowLine := strings.Repeat(t.rowChar, totalLineLength)
-	fmt.Println(headerLine)
-	t.printRow(tableHeader, tableSpec)
-	fmt.Println(headerLine)
-	for _, row := range tableRows {
-		t.printRow(row, tableSpec)
-		fmt.Println(rowLine)
-	}
-	fmt.Printf("Got %d rows, %d columns.", rowSize, columnSize)
-	fmt.Println()
+	header := make([]string, columnSize)
+	for j, name := range tableHeader {
+		header[j] = fit(j, name)
+	}
+	printHeader := func() {
+		fmt.Fprintln(out, headerLine)
+		t.printCells(header, nil, tableSpec, wrap)
+		fmt.Fprintln(out, headerLine)
+	}
+	tableRow := make([]string, columnSize)
+	for i, row := range table.GetRows() {
+		if i == 0 || (paged && i%pageRows == 0) {
+			printHeader()
+		}
+		for j, col := range row.GetColumns() {
+			tableRow[j] = fit(j, cell(j, col))
+		}
+		t.printCells(tableRow, row.GetColumns(), tableSpec, wrap)
+		fmt.Fprintln(out, rowLine)
+		if (i + 1) % flushRows == 0 {
+			out.Flush()
+		}
+	}
+	if rowSize == 0 {
+		printHeader()
+	}
+	printRowCount(rowSize, columnSize)
+}
+
+// Print each row as `column: value` pairs like MySQL's \G
+func (t Table) PrintVertical(table *graph.DataSet) {
+	out.buffer()
+	defer out.unbuffer()
+	columnSize := len(table.GetColumnNames())
+	rowSize := len(table.GetRows())
+	nameWidth := uint(0)
+	for _, header := range table.GetColumnNames() {
+		nameWidth = max(uint(stringWidth(string(header))), nameWidth)
+	}
+	secret := secretColumns(columnNames(table))
+	for i, row := range table.GetRows() {
+		fmt.Fprintf(out, "%s %d. row %s", strings.Repeat("*", 27), i+1, strings.Repeat("*", 27))
+		fmt.Fprintln(out)
+		for j, col := range row.GetColumns() {
+			name := string(table.GetColumnNames()[j])
+			value := decorate(val2String(col, 256), col)
+			if secret != nil && secret[j] {
+				value = secretMask
+			}
+			fmt.Fprintf(out, "%s%s: %s", strings.Repeat(" ", int(nameWidth)-stringWidth(name)), name, value)
+			fmt.Fprintln(out)
+		}
+		if (i + 1) % flushRows == 0 {
+			out.Flush()
+		}
+	}
+	printRowCount(rowSize, columnSize)
+}
+
+// Escape the cell of the GitHub-flavored Markdown table
+// The backslash first, otherwise the trailing one escapes the following delimiter
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r\n", "<br>", "\n", "<br>")
+
+// Print the GitHub-flavored Markdown table, the numeric columns right aligned
+func (t Table) PrintMarkdown(table *graph.DataSet) {
+	out.buffer()
+	defer out.unbuffer()
+	header := columnNames(table)
+	secret := secretColumns(header)
+	cells := make([]string, len(header))
+	for i, name := range header {
+		cells[i] = markdownEscaper.Replace(name)
+	}
+	fmt.Fprintf(out, "| %s |", strings.Join(cells, " | "))
+	fmt.Fprintln(out)
+	for i := range header {
+		cells[i] = "---"
+		if rows := table.GetRows(); len(rows) > 0 && i < len(rows[0].GetColumns()) {
+			if col := rows[0].GetColumns()[i]; col.IsSetIVal() || col.IsSetFVal() {
+				cells[i] = "---:"
+			}
+		}
+	}
+	fmt.Fprintf(out, "| %s |", strings.Join(cells, " | "))
+	fmt.Fprintln(out)
+	for _, row := range table.GetRows() {
+		// The short row is padded by the empty cells instead of the previous row's
+		for j := range cells {
+			cells[j] = ""
+		}
+		for j, col := range row.GetColumns() {
+			if j >= len(cells) {
+				break
+			}
+			if secret != nil && secret[j] {
+				cells[j] = secretMask
+			} else {
+				cells[j] = markdownEscaper.Replace(val2String(col, 256))
+			}
+		}
+		fmt.Fprintf(out, "| %s |", strings.Join(cells, " | "))
+		fmt.Fprintln(out)
+	}
+	if !quiet {
+		fmt.Fprintln(out)
+	}
+	printRowCount(len(table.GetRows()), len(header))
 }
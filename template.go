@@ -0,0 +1,161 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// The template parameter `{name}` in statements
+type templateParam struct {
+	name         string
+	defaultValue string // required if empty
+	usage        string
+}
+
+// The diagnostic statements shipped with the console
+type template struct {
+	usage      string
+	params     []templateParam
+	statements []string
+}
+
+var templates = map[string]template{
+	"supernodes": {
+		"Find the vertices whose degree exceeds the threshold",
+		[]templateParam{
+			{"tag", "", "The tag of vertices"},
+			{"threshold", "1000", "The minimum degree"},
+			{"limit", "100", "The max rows returned"},
+		},
+		[]string{
+			"MATCH (v:{tag})-[e]-() WITH v, count(e) AS degree WHERE degree > {threshold} " +
+				"RETURN id(v) AS vid, degree ORDER BY degree DESC LIMIT {limit}",
+		},
+	},
+	"orphans": {
+		"Find the vertices without any edge",
+		[]templateParam{
+			{"tag", "", "The tag of vertices"},
+			{"limit", "100", "The max rows returned"},
+		},
+		[]string{
+			"MATCH (v:{tag}) WHERE NOT (v)--() RETURN id(v) AS vid LIMIT {limit}",
+		},
+	},
+	"index-coverage": {
+		"Show the tag properties and the indexes over them",
+		[]templateParam{
+			{"tag", "", "The tag to check"},
+		},
+		[]string{
+			"DESCRIBE TAG {tag}",
+			"SHOW TAG INDEXES",
+		},
+	},
+	"stats": {
+		"Collect and show the vertices and edges count of the current space",
+		[]templateParam{},
+		[]string{
+			"SUBMIT JOB STATS",
+			"SHOW STATS",
+		},
+	},
+}
+
+// Parse the `--name value` arguments
+func templateArgs(tmpl template, args []string) (map[string]string, error) {
+	values := map[string]string{}
+	for i := 0; i < len(args); i += 2 {
+		if !strings.HasPrefix(args[i], "--") || i+1 >= len(args) {
+			return nil, fmt.Errorf("Expect `--<name> <value>', got `%s'", args[i])
+		}
+		values[strings.TrimPrefix(args[i], "--")] = args[i+1]
+	}
+	for name := range values {
+		found := false
+		for _, p := range tmpl.params {
+			found = found || p.name == name
+		}
+		if !found {
+			return nil, fmt.Errorf("Unknown parameter `%s'", name)
+		}
+	}
+	for _, p := range tmpl.params {
+		if _, ok := values[p.name]; ok {
+			continue
+		}
+		if p.defaultValue == "" {
+			return nil, fmt.Errorf("Missing parameter `--%s', %s", p.name, p.usage)
+		}
+		values[p.name] = p.defaultValue
+	}
+	return values, nil
+}
+
+func printTemplate(name string, tmpl template) {
+	fmt.Printf("%s: %s", name, tmpl.usage)
+	fmt.Println()
+	for _, p := range tmpl.params {
+		if p.defaultValue == "" {
+			fmt.Printf("  --%s <value>  %s", p.name, p.usage)
+		} else {
+			fmt.Printf("  --%s <value>  %s, default %s", p.name, p.usage, p.defaultValue)
+		}
+		fmt.Println()
+	}
+}
+
+// :template list | :template show <name> | :template run <name> [--<param> <value>]...
+func templateCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		fields = []string{"list"}
+	}
+	switch strings.ToLower(fields[0]) {
+	case "list":
+		names := make([]string, 0, len(templates))
+		for name := range templates {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			printTemplate(name, templates[name])
+		}
+		return nil
+	case "show", "run":
+		if len(fields) < 2 {
+			return fmt.Errorf("Usage: :template %s <name>", fields[0])
+		}
+		tmpl, ok := templates[fields[1]]
+		if !ok {
+			return fmt.Errorf("Unknown template `%s'", fields[1])
+		}
+		if strings.ToLower(fields[0]) == "show" {
+			printTemplate(fields[1], tmpl)
+			for _, stmt := range tmpl.statements {
+				fmt.Println(stmt)
+			}
+			return nil
+		}
+		values, err := templateArgs(tmpl, fields[2:])
+		if err != nil {
+			return err
+		}
+		for _, stmt := range tmpl.statements {
+			for name, value := range values {
+				stmt = strings.Replace(stmt, "{"+name+"}", value, -1)
+			}
+			fmt.Println(stmt)
+			execute(client, c, stmt)
+		}
+		return nil
+	}
+	return fmt.Errorf("Usage: :template list | :template show <name> | :template run <name> [--<param> <value>]...")
+}
@@ -0,0 +1,32 @@
+//go:build !windows
+// +build !windows
+
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+type winsize struct {
+	row    uint16
+	col    uint16
+	xpixel uint16
+	ypixel uint16
+}
+
+// The rows of the terminal, 0 if unknown
+func terminalHeight(fd uintptr) int {
+	ws := winsize{}
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, fd, uintptr(syscall.TIOCGWINSZ), uintptr(unsafe.Pointer(&ws)))
+	if errno != 0 {
+		return 0
+	}
+	return int(ws.row)
+}
@@ -0,0 +1,15 @@
+//go:build windows
+// +build windows
+
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+// The rows of the terminal, 0 if unknown
+func terminalHeight(fd uintptr) int {
+	return 0
+}
@@ -0,0 +1,108 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net"
+	"time"
+
+	"github.com/facebook/fbthrift/thrift/lib/go/thrift"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// Set by `--enable-ssl`, nil means plaintext
+var tlsConf *tls.Config
+
+func newTLSConfig(rootCA string, cert string, key string, insecureSkipVerify bool) (*tls.Config, error) {
+	c := &tls.Config{InsecureSkipVerify: insecureSkipVerify}
+	if rootCA != "" {
+		pem, err := ioutil.ReadFile(rootCA)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("No certificate found in %s", rootCA)
+		}
+		c.RootCAs = pool
+	}
+	if cert != "" || key != "" {
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{pair}
+	}
+	return c, nil
+}
+
+// The same timeout as the plaintext client
+const tlsTimeout = 30 * time.Second
+
+// The client of the graph service over the TLS socket, since the plaintext one dials the socket itself
+type tlsGraphClient struct {
+	graph     *graph.GraphServiceClient
+	sessionID int64
+}
+
+func newTLSClient(address string, c *tls.Config) (*tlsGraphClient, error) {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	c = c.Clone()
+	if c.ServerName == "" {
+		c.ServerName = host
+	}
+	sock, err := thrift.NewSSLSocketTimeout(address, c, tlsTimeout)
+	if err != nil {
+		return nil, err
+	}
+	transport := thrift.NewBufferedTransport(sock, 128<<10)
+	return &tlsGraphClient{graph: graph.NewGraphServiceClientFactory(transport, thrift.NewBinaryProtocolFactoryDefault())}, nil
+}
+
+// Open the TLS connection and authenticate, closed if failed
+func (client *tlsGraphClient) Connect(username, password string) error {
+	if err := client.graph.Transport.Open(); err != nil {
+		return err
+	}
+	resp, err := client.graph.Authenticate([]byte(username), []byte(password))
+	if err != nil {
+		client.graph.Close()
+		return err
+	}
+	if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		client.graph.Close()
+		return fmt.Errorf("%s", resp.GetErrorMsg())
+	}
+	client.sessionID = resp.GetSessionID()
+	return nil
+}
+
+// Signout and close the connection
+func (client *tlsGraphClient) Disconnect() {
+	if client.sessionID != 0 {
+		if err := client.graph.Signout(client.sessionID); err != nil {
+			log.Printf("Fail to signout, error: %s", err.Error())
+		}
+	}
+	if client.graph.Transport.IsOpen() {
+		if err := client.graph.Close(); err != nil {
+			log.Printf("Fail to close transport, error: %s", err.Error())
+		}
+	}
+}
+
+func (client *tlsGraphClient) Execute(stmt string) (*graph.ExecutionResponse, error) {
+	return client.graph.Execute(client.sessionID, []byte(stmt))
+}
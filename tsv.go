@@ -0,0 +1,50 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"io"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The tab, newline and backslash in the values are escaped like `\t`, so each line is one row for cut/awk
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// The header line then the rows, the cells of the secret columns masked if secret is not nil
+func writeTSV(w io.Writer, table *graph.DataSet, opts exportOptions, secret []bool) error {
+	header := columnNames(table)
+	cells := make([]string, len(header))
+	for i, name := range header {
+		cells[i] = tsvEscaper.Replace(name)
+	}
+	if _, err := io.WriteString(w, strings.Join(cells, "\t")+"\n"); err != nil {
+		return err
+	}
+	for _, row := range table.GetRows() {
+		cells = cells[:0]
+		for j, col := range row.GetColumns() {
+			if secret != nil && secret[j] {
+				cells = append(cells, secretMask)
+			} else {
+				cells = append(cells, tsvEscaper.Replace(opts.value(col)))
+			}
+		}
+		if _, err := io.WriteString(w, strings.Join(cells, "\t")+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Print the tab-separated values, the strings unquoted like the CSV export
+func (t Table) PrintTSV(table *graph.DataSet) {
+	out.buffer()
+	defer out.unbuffer()
+	writeTSV(out, table, exportOptions{binary: binaryStrings}, secretColumns(columnNames(table)))
+}
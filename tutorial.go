@@ -0,0 +1,205 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+// The step of the lesson, passed by the succeeded statement matching the expected one
+type tutorialStep struct {
+	title   string
+	text    string
+	example string
+	expect  *regexp.Regexp
+	// Validate the result further, nil if succeeded is enough
+	check func(resp *graph.ExecutionResponse) error
+}
+
+// The space of the lesson, kept after the tutorial for exploring
+const tutorialSpace = "nba_tutorial"
+
+// At least the rows returned
+func expectRows(n int) func(resp *graph.ExecutionResponse) error {
+	return func(resp *graph.ExecutionResponse) error {
+		rows := 0
+		for _, table := range resp.GetData() {
+			rows += len(table.GetRows())
+		}
+		if rows < n {
+			return fmt.Errorf("Expect at least %d rows but got %d, was the sample data inserted?", n, rows)
+		}
+		return nil
+	}
+}
+
+var tutorialSteps = []tutorialStep{
+	{
+		title:   "Create the space",
+		text:    "The space is the isolated graph like the database of SQL, the vertex IDs (VIDs) are the strings up to vid_size bytes.",
+		example: "CREATE SPACE IF NOT EXISTS " + tutorialSpace + "(partition_num = 1, replica_factor = 1, vid_size = 30);",
+		expect:  regexp.MustCompile(`(?i)^\s*CREATE\s+SPACE\s+(IF\s+NOT\s+EXISTS\s+)?` + tutorialSpace + `\b`),
+	},
+	{
+		title:   "Use the space",
+		text:    "The following statements are executed in the current space shown by the prompt.",
+		example: "USE " + tutorialSpace + ";",
+		expect:  regexp.MustCompile(`(?i)^\s*USE\s+` + tutorialSpace + `\b`),
+	},
+	{
+		title:   "Create the tags",
+		text:    "The tag is the type of the vertices with the properties, create `player' and `team'.",
+		example: "CREATE TAG IF NOT EXISTS player(name string, age int); CREATE TAG IF NOT EXISTS team(name string);",
+		expect:  regexp.MustCompile(`(?i)^\s*CREATE\s+TAG\s+(IF\s+NOT\s+EXISTS\s+)?(player|team)\b`),
+	},
+	{
+		title:   "Create the edge types",
+		text:    "The edge type is the type of the directed edges, create `follow' between the players and `serve' from the players to the teams.",
+		example: "CREATE EDGE IF NOT EXISTS follow(degree int); CREATE EDGE IF NOT EXISTS serve(start_year int, end_year int);",
+		expect:  regexp.MustCompile(`(?i)^\s*CREATE\s+EDGE\s+(IF\s+NOT\s+EXISTS\s+)?(follow|serve)\b`),
+	},
+	{
+		title: "Insert the vertices",
+		text:  "The schema takes effect after the heartbeat of about 10 seconds, retry if `No schema found'. Type `:run' to insert the sample players and teams.",
+		example: `INSERT VERTEX player(name, age) VALUES "player100":("Tim Duncan", 42), "player101":("Tony Parker", 36), ` +
+			`"player102":("LaMarcus Aldridge", 33), "player103":("Manu Ginobili", 41); ` +
+			`INSERT VERTEX team(name) VALUES "team200":("Spurs"), "team201":("Trail Blazers");`,
+		expect: regexp.MustCompile(`(?i)^\s*INSERT\s+VERTEX\b`),
+	},
+	{
+		title: "Insert the edges",
+		text:  "The edge is identified by the source, the destination and the rank, type `:run' to insert the sample edges.",
+		example: `INSERT EDGE follow(degree) VALUES "player100"->"player101":(95), "player100"->"player103":(95), ` +
+			`"player101"->"player100":(95), "player101"->"player102":(90), "player102"->"player101":(75); ` +
+			`INSERT EDGE serve(start_year, end_year) VALUES "player100"->"team200":(1997, 2016), ` +
+			`"player101"->"team200":(1999, 2018), "player102"->"team201":(2006, 2015), "player103"->"team200":(2002, 2018);`,
+		expect: regexp.MustCompile(`(?i)^\s*INSERT\s+EDGE\b`),
+	},
+	{
+		title:   "Traverse by GO",
+		text:    "GO walks the edges from the vertices, e.g. the players followed by Tim Duncan.",
+		example: `GO FROM "player100" OVER follow YIELD follow._dst AS id, follow.degree AS degree;`,
+		expect:  regexp.MustCompile(`(?i)^\s*GO\b`),
+		check:   expectRows(1),
+	},
+	{
+		title:   "Fetch the properties",
+		text:    "FETCH reads the properties of the vertices or the edges by the IDs.",
+		example: `FETCH PROP ON player "player100";`,
+		expect:  regexp.MustCompile(`(?i)^\s*FETCH\s+PROP\s+ON\b`),
+		check:   expectRows(1),
+	},
+	{
+		title:   "Match the pattern",
+		text:    "MATCH finds the pattern like openCypher, e.g. the teams served by the players Tim Duncan follows.",
+		example: `MATCH (p:player)-[:follow]->(f:player)-[:serve]->(t:team) WHERE id(p) == "player100" RETURN f.name, t.name;`,
+		expect:  regexp.MustCompile(`(?i)^\s*MATCH\b`),
+		check:   expectRows(1),
+	},
+}
+
+// The input passes the step if it's the succeeded expected statement with the valid result
+func passTutorialStep(step tutorialStep, stmt string, resp *graph.ExecutionResponse) error {
+	// The multiple statements separated by `;` like the examples
+	for _, s := range strings.Split(stmt, ";") {
+		if step.expect.MatchString(s) {
+			if resp == nil {
+				return fmt.Errorf("The statement failed, type `:hint' for the example")
+			}
+			if step.check != nil {
+				return step.check(resp)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("Not the expected statement, type `:hint' for the example or `:skip' to skip the step")
+}
+
+func printTutorialStep(i int) {
+	step := tutorialSteps[i]
+	fmt.Printf("[TUTORIAL] Step %d/%d: %s", i+1, len(tutorialSteps), step.title)
+	fmt.Println()
+	fmt.Println(step.text)
+	fmt.Println("Type `:hint' for the example, `:run' to execute it, `:skip' to skip the step or `:quit' to leave the tutorial.")
+	fmt.Println()
+}
+
+// The lesson in progress by `--tutorial`, nil if not started or left
+type tutorial struct {
+	step int
+}
+
+var activeTutorial *tutorial
+
+// Guide the lesson step by step through the interactive loop, the other statements and commands work as usual
+func startTutorial() {
+	fmt.Printf("Welcome to the tutorial, the sample NBA data is created in the space `%s'.", tutorialSpace)
+	fmt.Println()
+	fmt.Println()
+	activeTutorial = &tutorial{}
+	printTutorialStep(0)
+}
+
+// Handle the tutorial commands typed at the prompt, the line to continue with or handled,
+// e.g. `:run` continues with the example of the step
+func (t *tutorial) command(line string) (string, bool) {
+	if t == nil {
+		return line, false
+	}
+	switch strings.TrimSpace(line) {
+	case ":hint":
+		fmt.Println(tutorialSteps[t.step].example)
+		fmt.Println()
+		return line, true
+	case ":skip":
+		fmt.Println()
+		t.next()
+		return line, true
+	case ":quit":
+		fmt.Println("Left the tutorial.")
+		fmt.Println()
+		activeTutorial = nil
+		return line, true
+	case ":run":
+		fmt.Println(tutorialSteps[t.step].example)
+		return tutorialSteps[t.step].example, false
+	}
+	return line, false
+}
+
+// Check the executed statement against the step, the response is nil if failed
+func (t *tutorial) executed(stmt string, resp *graph.ExecutionResponse) {
+	if t == nil {
+		return
+	}
+	if err := passTutorialStep(tutorialSteps[t.step], stmt, resp); err != nil {
+		fmt.Printf("[TUTORIAL] %s", err.Error())
+		fmt.Println()
+		fmt.Println()
+		return
+	}
+	fmt.Printf("[TUTORIAL] Step %d passed.", t.step+1)
+	fmt.Println()
+	fmt.Println()
+	t.next()
+}
+
+func (t *tutorial) next() {
+	if t.step++; t.step < len(tutorialSteps) {
+		printTutorialStep(t.step)
+		return
+	}
+	fmt.Printf("[TUTORIAL] Congratulations, all steps passed, keep exploring the space `%s' or drop it by `DROP SPACE %s;'.",
+		tutorialSpace, tutorialSpace)
+	fmt.Println()
+	fmt.Println()
+	activeTutorial = nil
+}
@@ -0,0 +1,180 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	intTypePattern         = regexp.MustCompile(`^int(8|16|32|64)?$`)
+	fixedStringTypePattern = regexp.MustCompile(`^fixed_string\((\d+)\)$`)
+)
+
+// The layouts accepted by the date/datetime/time functions
+var (
+	dateLayouts     = []string{"2006-01-02"}
+	datetimeLayouts = []string{"2006-01-02T15:04:05.999999", "2006-01-02 15:04:05.999999", "2006-01-02"}
+	timeLayouts     = []string{"15:04:05.999999"}
+)
+
+func parseLayouts(value string, layouts []string) bool {
+	for _, layout := range layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Check the non-empty field against the property type, returns the reason if invalid
+func checkProp(value string, typ string) string {
+	if m := intTypePattern.FindStringSubmatch(typ); m != nil {
+		bits := 64
+		if m[1] != "" {
+			bits, _ = strconv.Atoi(m[1])
+		}
+		if _, err := strconv.ParseInt(value, 10, bits); err != nil {
+			return fmt.Sprintf("not an integer in range of %s", typ)
+		}
+		return ""
+	}
+	if m := fixedStringTypePattern.FindStringSubmatch(typ); m != nil {
+		if n, _ := strconv.Atoi(m[1]); len(value) > n {
+			return fmt.Sprintf("%d bytes, longer than %s", len(value), typ)
+		}
+		return ""
+	}
+	valid := true
+	switch typ {
+	case "double", "float":
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case "bool":
+		valid = strings.EqualFold(value, "true") || strings.EqualFold(value, "false")
+	case "timestamp":
+		_, err := strconv.ParseInt(value, 10, 64)
+		valid = err == nil
+	case "date":
+		valid = parseLayouts(value, dateLayouts)
+	case "datetime":
+		valid = parseLayouts(value, datetimeLayouts)
+	case "time":
+		valid = parseLayouts(value, timeLayouts)
+	}
+	if !valid {
+		return fmt.Sprintf("not a valid %s", typ)
+	}
+	return ""
+}
+
+// The violations of the row, the row number counts from 1 including the header
+func (im *importer) checkRecord(rowNumber int, header []string, cols importColumns, record []string) []string {
+	violations := []string{}
+	violate := func(column string, value string, reason string) {
+		violations = append(violations, fmt.Sprintf("Row %d, column `%s': `%s' is %s", rowNumber, column, value, reason))
+	}
+	if len(record) != len(header) {
+		return append(violations, fmt.Sprintf("Row %d: expect %d fields, got %d", rowNumber, len(header), len(record)))
+	}
+	ids := []int{cols.id}
+	if cols.dst >= 0 {
+		ids = append(ids, cols.dst)
+	}
+	for _, c := range ids {
+		if record[c] == "" {
+			violate(header[c], record[c], "an empty id")
+		} else if im.intVid {
+			if reason := checkProp(record[c], "int64"); reason != "" {
+				violate(header[c], record[c], "not an integer id")
+			}
+		}
+	}
+	if cols.rank >= 0 && record[cols.rank] != "" {
+		if reason := checkProp(record[cols.rank], "int64"); reason != "" {
+			violate(header[cols.rank], record[cols.rank], "not an integer rank")
+		}
+	}
+	for _, c := range cols.props {
+		name := header[c]
+		if record[c] == "" && im.emptyIsNull(im.schema[name]) {
+			if im.notNull[name] {
+				violate(name, record[c], "empty for the NOT NULL property")
+			}
+			continue
+		}
+		if reason := checkProp(record[c], im.schema[name]); reason != "" {
+			violate(name, record[c], reason)
+		}
+	}
+	return violations
+}
+
+// Validate all rows before inserting any, returns the reader from the beginning again
+// The unseekable input like the pipe is buffered in memory
+func (im *importer) validate(r io.Reader) (io.Reader, error) {
+	rs, ok := r.(io.ReadSeeker)
+	if ok {
+		_, err := rs.Seek(0, io.SeekCurrent)
+		ok = err == nil
+	}
+	if !ok {
+		content, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		rs = bytes.NewReader(content)
+	}
+	start, err := rs.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+
+	csvReader := im.newReader(rs)
+	// The field counts are checked with the row numbers
+	csvReader.FieldsPerRecord = -1
+	reader := im.records(csvReader)
+	header, cols, first, err := im.readHeader(reader)
+	if err != nil {
+		return nil, err
+	}
+	violations := []string{}
+	rowNumber := 1
+	if first != nil {
+		violations = append(violations, im.checkRecord(rowNumber, header, cols, first)...)
+	}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rowNumber++
+		violations = append(violations, im.checkRecord(rowNumber, header, cols, record)...)
+	}
+	if len(violations) > 0 {
+		im.progress.clear()
+		for _, violation := range violations {
+			fmt.Printf("[INVALID] %s", violation)
+			fmt.Println()
+		}
+		return nil, fmt.Errorf("%d invalid fields, nothing imported", len(violations))
+	}
+	if _, err = rs.Seek(start, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return rs, nil
+}
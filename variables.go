@@ -0,0 +1,64 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The client side variables set by `:let <name> <value>`, `:set <name> <value>` or `--param name=value`
+var variables = map[string]string{}
+
+var variablePattern = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// Replace the `${name}` by the variable value before sending the statement
+func substituteVariables(stmt string) (string, error) {
+	var err error
+	result := variablePattern.ReplaceAllStringFunc(stmt, func(ref string) string {
+		name := variablePattern.FindStringSubmatch(ref)[1]
+		value, ok := variables[name]
+		if !ok {
+			if err == nil {
+				err = fmt.Errorf("Undefined variable `%s'", name)
+			}
+			return ref
+		}
+		return value
+	})
+	return result, err
+}
+
+// The repeatable `--param name=value` flag
+type paramFlag struct{}
+
+func (paramFlag) String() string {
+	return ""
+}
+
+func (paramFlag) Set(param string) error {
+	kv := strings.SplitN(param, "=", 2)
+	if len(kv) != 2 || !variablePattern.MatchString("${"+kv[0]+"}") {
+		return fmt.Errorf("Expect name=value, got `%s'", param)
+	}
+	variables[kv[0]] = kv[1]
+	return nil
+}
+
+// :unset <name>
+func unsetCmd(client *Session, c Cli, args string) error {
+	name := strings.TrimSpace(args)
+	if name == "" {
+		return fmt.Errorf("Usage: :unset <name>")
+	}
+	if _, ok := variables[name]; !ok {
+		return fmt.Errorf("Undefined variable `%s'", name)
+	}
+	delete(variables, name)
+	return nil
+}
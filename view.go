@@ -0,0 +1,87 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	readline "github.com/shylock-hg/readline"
+)
+
+// The cell of the last result by 1-based row number and column name or 1-based index
+func lastCell(rowNumber int, column string) (*common.Value, error) {
+	if lastResp == nil || len(lastResp.GetData()) == 0 {
+		return nil, fmt.Errorf("No result")
+	}
+	table := lastResp.GetData()[0]
+	if rowNumber <= 0 || rowNumber > len(table.GetRows()) {
+		return nil, fmt.Errorf("Row %d out of range [1, %d]", rowNumber, len(table.GetRows()))
+	}
+	index := -1
+	for i, name := range columnNames(table) {
+		if name == column {
+			index = i
+		}
+	}
+	if index < 0 {
+		n, err := strconv.Atoi(column)
+		if err != nil || n <= 0 || n > len(table.GetColumnNames()) {
+			return nil, fmt.Errorf("Column `%s' not found", column)
+		}
+		index = n - 1
+	}
+	return table.GetRows()[rowNumber-1].GetColumns()[index], nil
+}
+
+func editorCommand() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	return "vi"
+}
+
+// :view <row> <column> [editor]
+func viewCmd(client *Session, c Cli, args string) error {
+	fields := strings.Fields(args)
+	if len(fields) < 2 || len(fields) > 3 || (len(fields) == 3 && fields[2] != "editor") {
+		return fmt.Errorf("Usage: :view <row> <column> [editor]")
+	}
+	rowNumber, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return fmt.Errorf("Invalid row number `%s'", fields[0])
+	}
+	value, err := lastCell(rowNumber, fields[1])
+	if err != nil {
+		return err
+	}
+	// The full content without truncating
+	content := exportValue(value)
+
+	if !readline.IsTerminal(int(os.Stdout.Fd())) {
+		fmt.Println(content)
+		return nil
+	}
+	if len(fields) == 3 {
+		tmp, err := ioutil.TempFile("", "nebula-view-*.txt")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(tmp.Name())
+		_, err = tmp.WriteString(content)
+		tmp.Close()
+		if err != nil {
+			return err
+		}
+		return runTerminalCommand(fmt.Sprintf("%s %s", editorCommand(), tmp.Name()), os.Stdin)
+	}
+	return runTerminalCommand(pagerCommand(), strings.NewReader(content+"\n"))
+}
@@ -0,0 +1,200 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	common "github.com/shylock-hg/nebula-go2.0/nebula"
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+const defaultWatchInterval = 2 * time.Second
+
+func numericValue(value *common.Value) (float64, bool) {
+	switch {
+	case value.IsSetIVal():
+		return float64(value.GetIVal()), true
+	case value.IsSetFVal():
+		return value.GetFVal(), true
+	}
+	return 0, false
+}
+
+// The row identity between refreshes, by the non-numeric columns
+func trendKey(row *graph.Row) string {
+	parts := []string{}
+	for _, col := range row.GetColumns() {
+		if _, ok := numericValue(col); !ok {
+			parts = append(parts, exactString(col))
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
+
+func formatDelta(delta float64) string {
+	s := strconv.FormatFloat(delta, 'g', -1, 64)
+	switch {
+	case delta > 0:
+		return "↑+" + s
+	case delta < 0:
+		return "↓" + s
+	}
+	return "→"
+}
+
+// The trend of the numeric columns compared to the previous refresh, named if more than one
+func rowTrend(header []string, row *graph.Row, previous *graph.Row) string {
+	if previous == nil {
+		return "new"
+	}
+	numeric := 0
+	for _, col := range row.GetColumns() {
+		if _, ok := numericValue(col); ok {
+			numeric++
+		}
+	}
+	trends := []string{}
+	for i, col := range row.GetColumns() {
+		v, ok := numericValue(col)
+		if !ok || i >= len(previous.GetColumns()) {
+			continue
+		}
+		p, ok := numericValue(previous.GetColumns()[i])
+		if !ok {
+			continue
+		}
+		trend := formatDelta(v - p)
+		if numeric > 1 {
+			trend = header[i] + " " + trend
+		}
+		trends = append(trends, trend)
+	}
+	return strings.Join(trends, ", ")
+}
+
+// Append the trend column to the table, and returns the rows by key for the next refresh
+// The previous is nil for the first refresh
+func withTrend(table *graph.DataSet, previous map[string]*graph.Row) (*graph.DataSet, map[string]*graph.Row) {
+	header := columnNames(table)
+	trended := &graph.DataSet{ColumnNames: append(append([][]byte{}, table.GetColumnNames()...), []byte("trend"))}
+	current := map[string]*graph.Row{}
+	for _, row := range table.GetRows() {
+		key := trendKey(row)
+		trend := []byte{}
+		if previous != nil {
+			trend = []byte(rowTrend(header, row, previous[key]))
+		}
+		current[key] = row
+		columns := append(append([]*common.Value{}, row.GetColumns()...), &common.Value{SVal: trend})
+		trended.Rows = append(trended.Rows, &graph.Row{Columns: columns})
+	}
+	return trended, current
+}
+
+// The interval like 5s, or the seconds like 5
+func parseWatchInterval(s string) (time.Duration, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return time.Duration(n) * time.Second, n > 0
+	}
+	d, err := time.ParseDuration(s)
+	return d, err == nil && d > 0
+}
+
+// :watch [<interval>|--interval <interval>] [--trend] <statement>
+// Execute the statement repeatedly until Ctrl+C, the trend column shows the changes of the numeric columns
+func watchCmd(client *Session, c Cli, args string) error {
+	interval := defaultWatchInterval
+	trend := false
+	args = strings.TrimSpace(args)
+	for strings.HasPrefix(args, "--") {
+		fields := strings.SplitN(args, " ", 2)
+		if len(fields) < 2 {
+			break
+		}
+		switch fields[0] {
+		case "--trend":
+			trend = true
+			args = strings.TrimSpace(fields[1])
+		case "--interval":
+			rest := strings.SplitN(strings.TrimSpace(fields[1]), " ", 2)
+			d, ok := parseWatchInterval(rest[0])
+			if !ok {
+				return fmt.Errorf("Invalid interval `%s', e.g. 5s", rest[0])
+			}
+			interval = d
+			args = ""
+			if len(rest) == 2 {
+				args = strings.TrimSpace(rest[1])
+			}
+		default:
+			return fmt.Errorf("Unknown option `%s'", fields[0])
+		}
+	}
+	// The positional interval, e.g. `:watch 5 SHOW HOSTS`
+	if fields := strings.SplitN(args, " ", 2); len(fields) == 2 {
+		if d, ok := parseWatchInterval(fields[0]); ok {
+			interval = d
+			args = strings.TrimSpace(fields[1])
+		}
+	}
+	if args == "" || strings.HasPrefix(args, "--") {
+		return fmt.Errorf("Usage: :watch [<interval>|--interval <interval>] [--trend] <statement>")
+	}
+	if err := confirmStatement(c, args); err != nil {
+		return err
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	defer signal.Stop(interrupt)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	previous := make([]map[string]*graph.Row, 0)
+	for {
+		resp, err := client.Execute(args)
+		if err != nil {
+			return err
+		}
+		if stdoutIsTTY {
+			// Clear the screen
+			fmt.Fprint(out, "\033[H\033[2J")
+		}
+		fmt.Fprintf(out, "Every %s: %s    %s", interval, args, time.Now().Format("2006-01-02 15:04:05"))
+		fmt.Fprintln(out)
+		if resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+			fmt.Fprint(out, errorColor(fmt.Sprintf("[ERROR (%d)] %s", resp.GetErrorCode(), errorString(resp))))
+			fmt.Fprintln(out)
+		}
+		for i, table := range resp.GetData() {
+			if trend {
+				if i >= len(previous) {
+					previous = append(previous, nil)
+				}
+				table, previous[i] = withTrend(table, previous[i])
+			}
+			t.PrintTable(table)
+		}
+		fmt.Fprintln(out, "Press Ctrl+C to stop.")
+		stopped := false
+		withoutTerminal(func() {
+			select {
+			case <-interrupt:
+				stopped = true
+			case <-ticker.C:
+			}
+		})
+		if stopped {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/* Copyright (c) 2020 vesoft inc. All rights reserved.
+ *
+ * This source code is licensed under Apache 2.0 License,
+ * attached with Common Clause Condition 1.0, found in the LICENSES directory.
+ */
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	graph "github.com/shylock-hg/nebula-go2.0/nebula/graph"
+)
+
+var errWizardAbort = fmt.Errorf("Wizard aborted")
+
+func wizardAsk(c Cli, question string, defaultAnswer string) (string, error) {
+	prompt := question
+	if defaultAnswer != "" {
+		prompt += fmt.Sprintf(" [%s]", defaultAnswer)
+	}
+	answer, err, exit := c.Ask(prompt + ": ")
+	if exit {
+		if err == nil {
+			err = errWizardAbort
+		}
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		answer = defaultAnswer
+	}
+	return answer, nil
+}
+
+// The schema names returned by `SHOW TAGS` or `SHOW EDGES`
+func schemaNames(client *Session, stmt string) []string {
+	names := []string{}
+	resp, err := client.Execute(stmt)
+	if err != nil || resp.GetErrorCode() != graph.ErrorCode_SUCCEEDED {
+		return names
+	}
+	for _, table := range resp.GetData() {
+		for _, row := range table.GetRows() {
+			// The name is the last column
+			columns := row.GetColumns()
+			if len(columns) > 0 {
+				names = append(names, exportValue(columns[len(columns)-1]))
+			}
+		}
+	}
+	return names
+}
+
+// Complete each input by the unique candidate prefixed by it
+func completeNames(input []string, candidates []string) ([]string, error) {
+	if len(candidates) == 0 {
+		return input, nil
+	}
+	completed := make([]string, 0, len(input))
+	for _, in := range input {
+		matched := []string{}
+		for _, cand := range candidates {
+			if cand == in {
+				matched = []string{cand}
+				break
+			}
+			if strings.HasPrefix(strings.ToLower(cand), strings.ToLower(in)) {
+				matched = append(matched, cand)
+			}
+		}
+		switch len(matched) {
+		case 0:
+			return nil, fmt.Errorf("Unknown edge type `%s'", in)
+		case 1:
+			completed = append(completed, matched[0])
+		default:
+			return nil, fmt.Errorf("Ambiguous edge type `%s', candidates: %s", in, strings.Join(matched, ", "))
+		}
+	}
+	return completed, nil
+}
+
+func splitList(s string) []string {
+	items := []string{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// :wizard go
+func wizardCmd(client *Session, c Cli, args string) error {
+	if strings.ToLower(strings.TrimSpace(args)) != "go" {
+		return fmt.Errorf("Usage: :wizard go")
+	}
+	vids, err := wizardAsk(c, "Source vertex ids (comma separated)", "")
+	if err != nil {
+		return err
+	}
+	if len(splitList(vids)) == 0 {
+		return fmt.Errorf("No source vertex id")
+	}
+	sources := []string{}
+	for _, vid := range splitList(vids) {
+		if !isQuoted(vid) && !isIntVidSpace(client) {
+			vid = quoteVid(vid)
+		}
+		sources = append(sources, vid)
+	}
+
+	edgeTypes := schemaNames(client, "SHOW EDGES")
+	if len(edgeTypes) > 0 {
+		fmt.Printf("Edge types: %s", strings.Join(edgeTypes, ", "))
+		fmt.Println()
+	}
+	defaultEdge := ""
+	if len(edgeTypes) == 1 {
+		defaultEdge = edgeTypes[0]
+	}
+	over, err := wizardAsk(c, "Edge types (comma separated, prefix completed)", defaultEdge)
+	if err != nil {
+		return err
+	}
+	edges, err := completeNames(splitList(over), edgeTypes)
+	if err != nil {
+		return err
+	}
+	if len(edges) == 0 {
+		return fmt.Errorf("No edge type")
+	}
+
+	steps, err := wizardAsk(c, "Steps", "1")
+	if err != nil {
+		return err
+	}
+	if n, err := strconv.Atoi(steps); err != nil || n <= 0 {
+		return fmt.Errorf("Expect a positive steps, got `%s'", steps)
+	}
+
+	yield, err := wizardAsk(c, "YIELD columns (comma separated)", edges[0]+"._dst")
+	if err != nil {
+		return err
+	}
+
+	stmt := fmt.Sprintf("GO %s STEPS FROM %s OVER %s", steps, strings.Join(sources, ", "), strings.Join(edges, ", "))
+	if columns := splitList(yield); len(columns) > 0 {
+		stmt += " YIELD " + strings.Join(columns, ", ")
+	}
+	fmt.Println(stmt)
+	confirm, err := wizardAsk(c, "Execute? (y/n)", "y")
+	if err != nil {
+		return err
+	}
+	if strings.ToLower(confirm) == "y" {
+		execute(client, c, stmt)
+	}
+	return nil
+}